@@ -0,0 +1,41 @@
+package cobra
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+)
+
+var (
+	amendDryRun  bool
+	rewordDryRun bool
+)
+
+var amendCmd = &cobra.Command{
+	Use:   "amend",
+	Short: "Regenerate the message of HEAD and amend it",
+	Long: `Generates a new commit message from the changes in HEAD plus any newly
+staged changes and runs 'git commit --amend' with it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		git.AmendCommit(ollamaURL, ollamaModel, fallbacks, tone, language, amendDryRun, commitOptions())
+	},
+}
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword <rev>",
+	Short: "Regenerate the message of an older commit",
+	Long: `Generates a new commit message from the changes in <rev> and rewrites
+that commit's message, using a non-interactive rebase for commits older
+than HEAD.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		git.RewordCommit(ollamaURL, ollamaModel, fallbacks, tone, language, args[0], rewordDryRun, commitOptions())
+	},
+}
+
+func init() {
+	amendCmd.Flags().BoolVar(&amendDryRun, "dry-run", false, "only show the regenerated message without amending")
+	rewordCmd.Flags().BoolVar(&rewordDryRun, "dry-run", false, "only show the regenerated message without rewording")
+	rootCmd.AddCommand(amendCmd)
+	rootCmd.AddCommand(rewordCmd)
+}
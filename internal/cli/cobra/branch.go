@@ -0,0 +1,39 @@
+package cobra
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+)
+
+var (
+	branchPattern string
+	branchTicket  string
+	branchSwitch  bool
+)
+
+var branchCmd = &cobra.Command{
+	Use:   "branch [task description]",
+	Short: "Propose a branch name for a task or the staged changes",
+	Long: `Suggests a branch name from a free-text task description or, when no
+description is given, from the staged diff. The name follows --pattern, which
+may use the {type}, {TICKET} and {slug} placeholders, and a ticket in the
+name is picked up again as the commit message prefix. With --switch the
+branch is created and checked out.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pattern := branchPattern
+		if !cmd.Flags().Changed("pattern") && cfg.Branch.Pattern != "" {
+			pattern = cfg.Branch.Pattern
+		}
+		git.SuggestBranchName(ollamaURL, ollamaModel, fallbacks, strings.Join(args, " "), pattern, branchTicket, branchSwitch)
+	},
+}
+
+func init() {
+	branchCmd.Flags().StringVar(&branchPattern, "pattern", git.DefaultBranchPattern, "branch name pattern using {type}, {TICKET} and {slug}")
+	branchCmd.Flags().StringVar(&branchTicket, "ticket", "", "ticket to include in the name (e.g. AUTH-12), detected from the description by default")
+	branchCmd.Flags().BoolVar(&branchSwitch, "switch", false, "create and switch to the branch with 'git switch -c'")
+	rootCmd.AddCommand(branchCmd)
+}
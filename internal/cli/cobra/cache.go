@@ -0,0 +1,30 @@
+package cobra
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of generated commit messages",
+	Long: `Generated commit messages are cached in the user cache directory, keyed by
+the diff, model, prompt templates and tone, so that rerunning snippety on the
+same changes returns the same message instantly.`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached commit messages",
+	Run: func(cmd *cobra.Command, args []string) {
+		git.ClearMessageCache(os.Stderr)
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,41 @@
+package cobra
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+)
+
+var (
+	changelogFrom    string
+	changelogTo      string
+	changelogTitle   string
+	changelogOffline bool
+	changelogAppend  bool
+	changelogFile    string
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generate release notes from commit history",
+	Long: `Reads the commits in a range, groups them by Conventional Commits type
+and renders a Keep a Changelog style markdown section. The model rewrites
+terse commit titles into user-facing entries unless --offline is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		appendFile := ""
+		if changelogAppend {
+			appendFile = changelogFile
+		}
+		git.GenerateChangelog(ollamaURL, ollamaModel, fallbacks, changelogFrom, changelogTo, changelogTitle, changelogOffline, appendFile)
+	},
+}
+
+func init() {
+	changelogCmd.Flags().StringVar(&changelogFrom, "from", "", "start of the range, exclusive (defaults to the latest tag)")
+	changelogCmd.Flags().StringVar(&changelogTo, "to", "HEAD", "end of the range, inclusive")
+	changelogCmd.Flags().StringVar(&changelogTitle, "title", "", "version heading (defaults to --to, or Unreleased for HEAD)")
+	changelogCmd.Flags().BoolVar(&changelogOffline, "offline", false, "use the raw commit titles without calling the model")
+	changelogCmd.Flags().BoolVar(&changelogAppend, "append", false, "insert the section into the changelog file instead of printing it, replacing one with the same title")
+	changelogCmd.Flags().StringVar(&changelogFile, "file", "CHANGELOG.md", "changelog file used with --append")
+	rootCmd.AddCommand(changelogCmd)
+}
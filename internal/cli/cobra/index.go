@@ -0,0 +1,22 @@
+package cobra
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Index past commits for similar-commit retrieval",
+	Long: `Embeds the diffs of recent commits with --embed-model and stores them in
+.git/snippety/embeddings.json. Generating with --similar indexes a few new
+commits on the fly, this command indexes the last 500 commits up front.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		git.IndexCommits(ollamaURL, embedModel)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(indexCmd)
+}
@@ -0,0 +1,31 @@
+package cobra
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+)
+
+var (
+	prBase     string
+	prTemplate string
+	prOutput   string
+)
+
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Generate a pull request title and description",
+	Long: `Collects the commits and combined diff between the current branch and its
+base (auto-detected from main, master or the remote default branch) and
+generates a pull request title with a markdown description.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		git.GeneratePullRequest(ollamaURL, ollamaModel, fallbacks, prBase, prTemplate, prOutput)
+	},
+}
+
+func init() {
+	prCmd.Flags().StringVar(&prBase, "base", "", "base branch to compare against (auto-detected by default)")
+	prCmd.Flags().StringVar(&prTemplate, "template", "", "markdown template whose section headings the description must fill")
+	prCmd.Flags().StringVarP(&prOutput, "output", "o", "", "write the description to a file instead of stdout")
+	rootCmd.AddCommand(prCmd)
+}
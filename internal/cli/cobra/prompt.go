@@ -0,0 +1,37 @@
+package cobra
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+)
+
+var promptLearnStyle bool
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Inspect the prompts sent to the model",
+	Long: `The commit prompt and the tone instructions are text/template files. The
+built-in templates can be overridden by placing commit.tmpl and
+tones/<name>.tmpl in the prompt directory (see "prompts.dir" in the config).`,
+}
+
+var promptShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Render the commit prompt for the staged changes",
+	Long: `Renders the commit message prompt for the staged changes with the current
+templates, tone and context and prints it without calling the model.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		learn := promptLearnStyle
+		if !cmd.Flags().Changed("learn-style") {
+			learn = cfg.Style.Learn
+		}
+		git.ShowPrompt(tone, language, learn)
+	},
+}
+
+func init() {
+	promptShowCmd.Flags().BoolVar(&promptLearnStyle, "learn-style", false, "include the learned commit style and examples")
+	promptCmd.AddCommand(promptShowCmd)
+	rootCmd.AddCommand(promptCmd)
+}
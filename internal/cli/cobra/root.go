@@ -3,22 +3,58 @@ package cobra
 import (
 	"fmt"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/tahcohcat/snippety/internal/cli/git"
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+	"github.com/tahcohcat/snippety/internal/config"
 )
 
 var (
 	ollamaURL   string
 	ollamaModel string
+	fallbacks   []string
+	jsonOutput  bool
+	noCache     bool
+	cacheTTL    time.Duration
 	showDiff    bool
 	tone        string
+	language    string
 	interactive bool
 	autoStage   bool
+	selectHunks bool
+	diffSource  string
+	diffRev     string
+	diffRange   string
+	patchFile   string
+	signoff     bool
+	coAuthors   []string
+	sign        bool
+	gpgSign     string
+	wrapWidth   int
+	learnStyle  bool
+	similar     int
+	embedModel  string
+	temperature float64
+	topP        float64
+	seed        int
+	numCtx      int
+	numPredict  int
+	stop        []string
+	keepAlive   string
+	pullModel   bool
+	timeouts    = ollama.DefaultTimeouts()
+	retries     int
 	debug       bool
 	showVersion bool
+
+	// cfg is loaded from the user and repository config files before any
+	// command runs
+	cfg config.Config
 )
 
 var rootCmd = &cobra.Command{
@@ -26,33 +62,282 @@ var rootCmd = &cobra.Command{
 	Short: "Generate commit messages from staged git diff using Ollama",
 	Long: `A CLI tool that analyzes your staged git changes and generates
 meaningful commit messages using Ollama AI based on the diff.`,
-	Run: func(cmd *cobra.Command, args []string) {
-
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		if debug {
 			logrus.SetLevel(logrus.DebugLevel)
 			logrus.Debug("debug mode enabled")
 		}
 
+		// Commands such as pr and --json print their result to stdout, so
+		// configuration warnings and errors go to stderr
+		loaded, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sWarning: %v%s\n", git.ColorYellow, err, git.ColorReset)
+		}
+		cfg = loaded
+		ollama.SetPromptDir(cfg.PromptDir())
+		ollama.SetTonePresets(tonePresets(cfg.Tones))
+
+		if !cmd.Flags().Changed("model") && cfg.Ollama.Model != "" {
+			ollamaModel = cfg.Ollama.Model
+		}
+		if !cmd.Flags().Changed("fallback-model") && cfg.Ollama.FallbackModels != nil {
+			fallbacks = cfg.Ollama.FallbackModels
+		}
+		if !cmd.Flags().Changed("wrap") && cfg.Message.Wrap != nil {
+			wrapWidth = *cfg.Message.Wrap
+		}
+		if !cmd.Flags().Changed("embed-model") && cfg.Retrieval.Model != "" {
+			embedModel = cfg.Retrieval.Model
+		}
+		if !cmd.Flags().Changed("language") && cfg.Message.Language != "" {
+			language = cfg.Message.Language
+		}
+		if err := ollama.ValidateLanguage(language); err != nil {
+			fmt.Fprintf(os.Stderr, "%s%v%s\n", git.ColorRed, err, git.ColorReset)
+			os.Exit(1)
+		}
+
+		options, alive := modelOptions(cmd)
+		if err := alive.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s%v%s\n", git.ColorRed, err, git.ColorReset)
+			os.Exit(1)
+		}
+		ollama.SetModelOptions(options, alive)
+
+		if err := applyConfigTimeouts(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "%s%v%s\n", git.ColorRed, err, git.ColorReset)
+			os.Exit(1)
+		}
+		retry := ollama.DefaultRetryPolicy()
+		retry.Retries = retries
+		ollama.SetTimeouts(timeouts, retry)
+
+		if pullModel {
+			// Like the warnings above, pull progress is kept out of stdout
+			for _, model := range modelsToPull(cmd) {
+				if !git.PullModel(ollamaURL, model, os.Stderr) {
+					os.Exit(1)
+				}
+			}
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
 		if showVersion {
 			fmt.Println("snippety v0.1.0")
 			return
 		}
 
-		git.GenerateCommitMessage(ollamaURL, ollamaModel, showDiff, tone, interactive, autoStage)
+		learn := learnStyle
+		if !cmd.Flags().Changed("learn-style") {
+			learn = cfg.Style.Learn
+		}
+		topK := similar
+		if !cmd.Flags().Changed("similar") {
+			topK = cfg.Retrieval.TopK
+		}
+		skipCache := noCache
+		if !cmd.Flags().Changed("no-cache") {
+			skipCache = cfg.Cache.Disabled
+		}
+		if err := applyConfigCacheTTL(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "%s%v%s\n", git.ColorRed, err, git.ColorReset)
+			os.Exit(1)
+		}
+
+		git.GenerateCommitMessage(git.GenerateOptions{
+			OllamaURL:      ollamaURL,
+			OllamaModel:    ollamaModel,
+			FallbackModels: fallbacks,
+			ShowDiff:       showDiff,
+			Tone:           tone,
+			Interactive:    interactive,
+			AutoStage:      autoStage,
+			SelectHunks:    selectHunks,
+			Source: git.DiffSource{
+				Kind:  diffSource,
+				Rev:   diffRev,
+				Range: diffRange,
+				Patch: patchFile,
+			},
+			Commit:          commitOptions(),
+			PairingPartners: cfg.Pairing.Partners,
+			LearnStyle:      learn,
+			SimilarCommits:  topK,
+			EmbedModel:      embedModel,
+			Language:        language,
+			JSON:            jsonOutput,
+			NoCache:         skipCache,
+			CacheTTL:        cacheTTL,
+		})
 	},
 }
 
 func init() {
-	rootCmd.Flags().StringVar(&ollamaURL, "ollama-url", "http://localhost:11434", "ollama server URL")
-	rootCmd.Flags().StringVar(&ollamaModel, "model", "llama3.2", "ollama model to use for generation")
+	rootCmd.PersistentFlags().StringVar(&ollamaURL, "ollama-url", "http://localhost:11434", "ollama server URL")
+	rootCmd.PersistentFlags().StringVar(&ollamaModel, "model", "llama3.2", "ollama model to use for generation")
+	rootCmd.PersistentFlags().StringArrayVar(&fallbacks, "fallback-model", nil, "model to try when the previous one is missing, times out or fails (repeatable, in order)")
+	rootCmd.Flags().BoolVar(&jsonOutput, "json", false, "print the generated message and the model that produced it as JSON")
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "generate a new message instead of reusing one cached for the same diff")
+	rootCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", git.DefaultCacheTTL, "how long cached messages are reused, 0 until 'snippety cache clear'")
 	rootCmd.Flags().BoolVar(&showDiff, "show-diff", false, "show git diff output to the user")
-	rootCmd.Flags().StringVar(&tone, "tone", "professional", "tone for commit messages (professional, fun, pirate, haiku, serious, or custom tone)")
+	rootCmd.PersistentFlags().StringVar(&tone, "tone", "professional", "tone for commit messages (professional, fun, pirate, haiku, serious, a preset from 'snippety tones list', or custom tone)")
+	rootCmd.PersistentFlags().StringVar(&language, "language", "", "language tag to write commit messages in (e.g. de, ja, pt-BR), English by default")
 	rootCmd.Flags().BoolVar(&interactive, "interactive", false, "interactively confirm before creating the git commit")
-	rootCmd.Flags().BoolVar(&autoStage, "auto-stage", true, "automatically stage all changes with 'git add -A' before generating commit message")
-	rootCmd.Flags().BoolVar(&debug, "debug", false, "enable debug mode")
+	rootCmd.PersistentFlags().BoolVar(&autoStage, "auto-stage", true, "automatically stage all changes with 'git add -A' before generating commit message")
+	rootCmd.Flags().BoolVar(&selectHunks, "select", false, "interactively pick the files and hunks to stage instead of staging everything")
+	rootCmd.Flags().StringVar(&diffSource, "diff-source", git.DiffSourceStaged, "where to read changes from (staged, unstaged, rev, range, patch)")
+	rootCmd.Flags().StringVar(&diffRev, "rev", "", "commit to describe, implies --diff-source=rev")
+	rootCmd.Flags().StringVar(&diffRange, "range", "", "revision range to describe (e.g. main..HEAD), implies --diff-source=range")
+	rootCmd.Flags().StringVar(&patchFile, "patch", "", "patch file to describe, '-' reads stdin, implies --diff-source=patch")
+	rootCmd.Flags().BoolVar(&learnStyle, "learn-style", false, "learn the commit style from git history and add examples to the prompt")
+	rootCmd.Flags().IntVar(&similar, "similar", 0, "add the messages of this many past commits with similar diffs as examples")
+	rootCmd.PersistentFlags().StringVar(&embedModel, "embed-model", ollama.DefaultEmbedModel, "ollama embedding model used to find similar commits")
+	rootCmd.PersistentFlags().Float64Var(&temperature, "temperature", ollama.DefaultTemperature, "sampling temperature, negative uses the model default")
+	rootCmd.PersistentFlags().Float64Var(&topP, "top-p", 0, "nucleus sampling threshold, 0 uses the model default")
+	rootCmd.PersistentFlags().IntVar(&seed, "seed", ollama.DefaultSeed, "random seed for reproducible messages, negative for a random seed")
+	rootCmd.PersistentFlags().IntVar(&numCtx, "num-ctx", 0, "context window in tokens, raise it for big diffs (0 uses the model default)")
+	rootCmd.PersistentFlags().IntVar(&numPredict, "num-predict", 0, "maximum number of tokens to generate, 0 uses the model default")
+	rootCmd.PersistentFlags().StringArrayVar(&stop, "stop", nil, "stop generating at this sequence (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&keepAlive, "keep-alive", "", "how long ollama keeps the model loaded, e.g. 10m, or seconds with -1 for ever")
+	rootCmd.PersistentFlags().DurationVar(&timeouts.Connect, "connect-timeout", timeouts.Connect, "time allowed to connect to ollama, 0 for no limit")
+	rootCmd.PersistentFlags().DurationVar(&timeouts.FirstToken, "first-token-timeout", timeouts.FirstToken, "time allowed for the model to load and start answering, 0 for no limit")
+	rootCmd.PersistentFlags().DurationVar(&timeouts.Total, "timeout", timeouts.Total, "time allowed for a whole request to ollama, 0 for no limit")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", ollama.DefaultRetryPolicy().Retries, "retries with backoff when ollama is starting up or loading the model")
+	rootCmd.PersistentFlags().BoolVar(&pullModel, "pull", false, "pull the model and the fallback models from the ollama registry first if they are not installed")
+	rootCmd.PersistentFlags().BoolVarP(&signoff, "signoff", "s", false, "add a Signed-off-by trailer for the configured git user")
+	rootCmd.PersistentFlags().StringArrayVar(&coAuthors, "co-author", nil, "add a Co-authored-by trailer for \"Name <email>\" (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&sign, "sign", false, "sign commits with the default key, also enabled by commit.gpgsign")
+	rootCmd.PersistentFlags().StringVar(&gpgSign, "gpg-sign", "", "sign commits, optionally with the given key id (--gpg-sign[=keyid])")
+	rootCmd.PersistentFlags().Lookup("gpg-sign").NoOptDefVal = defaultSigningKey
+	rootCmd.PersistentFlags().IntVar(&wrapWidth, "wrap", git.DefaultWrapWidth, "wrap the commit message body at this width, 0 disables wrapping")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug mode")
 	rootCmd.Flags().BoolVar(&showVersion, "version", false, "show version")
 }
 
+// defaultSigningKey is the value of a bare --gpg-sign, signing with the
+// key git picks by itself.
+const defaultSigningKey = "default"
+
+// commitOptions collects the trailer, signing and wrapping flags shared by
+// every command that creates commits.
+func commitOptions() git.CommitOptions {
+	opts := git.CommitOptions{
+		Signoff:   signoff,
+		CoAuthors: coAuthors,
+		Sign:      sign || gpgSign != "",
+		Wrap:      wrapWidth,
+	}
+	if gpgSign != defaultSigningKey {
+		opts.SigningKey = gpgSign
+	}
+	return opts
+}
+
+// modelOptions collects the Ollama model parameters from the flags, with
+// values from the config file for flags that were not given.
+func modelOptions(cmd *cobra.Command) (ollama.Options, ollama.KeepAlive) {
+	flags := cmd.Flags()
+	configured := cfg.Ollama.Options
+	if !flags.Changed("temperature") && configured.Temperature != nil {
+		temperature = *configured.Temperature
+	}
+	if !flags.Changed("top-p") && configured.TopP != nil {
+		topP = *configured.TopP
+	}
+	if !flags.Changed("seed") && configured.Seed != nil {
+		seed = *configured.Seed
+	}
+	if !flags.Changed("num-ctx") && configured.NumCtx != nil {
+		numCtx = *configured.NumCtx
+	}
+	if !flags.Changed("num-predict") && configured.NumPredict != nil {
+		numPredict = *configured.NumPredict
+	}
+	if !flags.Changed("stop") && configured.Stop != nil {
+		stop = configured.Stop
+	}
+	if !flags.Changed("keep-alive") && cfg.Ollama.KeepAlive != "" {
+		keepAlive = cfg.Ollama.KeepAlive
+	}
+
+	options := ollama.Options{NumCtx: numCtx, NumPredict: numPredict, Stop: stop}
+	if temperature >= 0 {
+		t := temperature
+		options.Temperature = &t
+	}
+	if topP > 0 {
+		p := topP
+		options.TopP = &p
+	}
+	if seed >= 0 {
+		s := seed
+		options.Seed = &s
+	}
+	return options, ollama.KeepAlive(keepAlive)
+}
+
+// applyConfigTimeouts sets the timeouts and retries from the config file
+// for flags that were not given.
+func applyConfigTimeouts(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	configured := []struct {
+		flag  string
+		value string
+		dest  *time.Duration
+	}{
+		{"connect-timeout", cfg.Ollama.Timeouts.Connect, &timeouts.Connect},
+		{"first-token-timeout", cfg.Ollama.Timeouts.FirstToken, &timeouts.FirstToken},
+		{"timeout", cfg.Ollama.Timeouts.Total, &timeouts.Total},
+	}
+	for _, c := range configured {
+		if flags.Changed(c.flag) || c.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(c.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s in config: %w", c.flag, err)
+		}
+		*c.dest = d
+	}
+	if !flags.Changed("retries") && cfg.Ollama.Retries != nil {
+		retries = *cfg.Ollama.Retries
+	}
+	if retries < 0 {
+		return fmt.Errorf("invalid retries %d, expected 0 or more", retries)
+	}
+	return nil
+}
+
+// modelsToPull returns the models cmd uses: the embedding model for index,
+// otherwise the model followed by the fallback models.
+func modelsToPull(cmd *cobra.Command) []string {
+	if cmd == indexCmd {
+		return []string{embedModel}
+	}
+	models := []string{ollamaModel}
+	for _, model := range fallbacks {
+		if !slices.Contains(models, model) {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// applyConfigCacheTTL sets the cache TTL from the config file unless
+// --cache-ttl was given.
+func applyConfigCacheTTL(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("cache-ttl") || cfg.Cache.TTL == "" {
+		return nil
+	}
+	ttl, err := time.ParseDuration(cfg.Cache.TTL)
+	if err != nil {
+		return fmt.Errorf("invalid cache-ttl in config: %w", err)
+	}
+	cacheTTL = ttl
+	return nil
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
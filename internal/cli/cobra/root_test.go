@@ -2,9 +2,15 @@ package cobra
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+	"github.com/tahcohcat/snippety/internal/config"
 )
 
 func TestRootCommand(t *testing.T) {
@@ -190,6 +196,136 @@ func TestToneValidation(t *testing.T) {
 	}
 }
 
+func TestTonePresetValidation(t *testing.T) {
+	tones := map[string]config.ToneConfig{
+		"release-notes": {Description: "For the changelog", Instruction: "Write for end users."},
+		"terse":         {Instruction: "Be brief.", Constraints: []string{"Keep the title under 50 characters"}},
+		"Company Style": {Instruction: "Follow the style guide."},
+		"empty":         {},
+	}
+
+	presets := tonePresets(tones)
+
+	var names []string
+	for _, preset := range presets {
+		names = append(names, preset.Name)
+	}
+	if len(names) != 2 || names[0] != "release-notes" || names[1] != "terse" {
+		t.Errorf("tonePresets() names = %v, want [release-notes terse]", names)
+	}
+}
+
+func TestModelOptions(t *testing.T) {
+	configuredSeed := 7
+	configuredCtx := 4096
+	cfg = config.Config{Ollama: config.OllamaConfig{
+		Options:   config.OllamaOptions{Seed: &configuredSeed, NumCtx: &configuredCtx},
+		KeepAlive: "10m",
+	}}
+	defer func() { cfg = config.Config{} }()
+
+	cmd := &cobra.Command{Use: "snippety", Run: func(cmd *cobra.Command, args []string) {}}
+	cmd.Flags().Float64Var(&temperature, "temperature", 0.2, "")
+	cmd.Flags().Float64Var(&topP, "top-p", 0, "")
+	cmd.Flags().IntVar(&seed, "seed", 42, "")
+	cmd.Flags().IntVar(&numCtx, "num-ctx", 0, "")
+	cmd.Flags().IntVar(&numPredict, "num-predict", 0, "")
+	cmd.Flags().StringArrayVar(&stop, "stop", nil, "")
+	cmd.Flags().StringVar(&keepAlive, "keep-alive", "", "")
+	cmd.SetArgs([]string{"--num-ctx", "16384", "--temperature", "-1"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() unexpected error: %v", err)
+	}
+
+	options, alive := modelOptions(cmd)
+	if options.Temperature != nil {
+		t.Errorf("Temperature = %v, want the model default", *options.Temperature)
+	}
+	if options.Seed == nil || *options.Seed != 7 {
+		t.Errorf("Seed = %v, want 7 from the config", options.Seed)
+	}
+	if options.NumCtx != 16384 {
+		t.Errorf("NumCtx = %d, want 16384 from the flag", options.NumCtx)
+	}
+	if alive != "10m" {
+		t.Errorf("KeepAlive = %q, want 10m from the config", alive)
+	}
+}
+
+func TestApplyConfigTimeouts(t *testing.T) {
+	configuredRetries := 0
+	cfg = config.Config{Ollama: config.OllamaConfig{
+		Timeouts: config.TimeoutsConfig{FirstToken: "5m", Total: "10m"},
+		Retries:  &configuredRetries,
+	}}
+	defer func() { cfg = config.Config{} }()
+	timeouts = ollama.DefaultTimeouts()
+
+	cmd := &cobra.Command{Use: "snippety", Run: func(cmd *cobra.Command, args []string) {}}
+	cmd.Flags().DurationVar(&timeouts.Connect, "connect-timeout", timeouts.Connect, "")
+	cmd.Flags().DurationVar(&timeouts.FirstToken, "first-token-timeout", timeouts.FirstToken, "")
+	cmd.Flags().DurationVar(&timeouts.Total, "timeout", timeouts.Total, "")
+	cmd.Flags().IntVar(&retries, "retries", 3, "")
+	cmd.SetArgs([]string{"--timeout", "15m"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() unexpected error: %v", err)
+	}
+
+	if err := applyConfigTimeouts(cmd); err != nil {
+		t.Fatalf("applyConfigTimeouts() unexpected error: %v", err)
+	}
+	expected := ollama.Timeouts{Connect: 10 * time.Second, FirstToken: 5 * time.Minute, Total: 15 * time.Minute}
+	if timeouts != expected {
+		t.Errorf("timeouts = %+v, want %+v", timeouts, expected)
+	}
+	if retries != 0 {
+		t.Errorf("retries = %d, want 0 from the config", retries)
+	}
+
+	cfg.Ollama.Timeouts.Connect = "soon"
+	if err := applyConfigTimeouts(cmd); err == nil {
+		t.Error("applyConfigTimeouts() expected an error for an invalid duration")
+	}
+}
+
+func TestApplyConfigCacheTTL(t *testing.T) {
+	cfg = config.Config{Cache: config.CacheConfig{TTL: "12h"}}
+	defer func() { cfg = config.Config{} }()
+	cacheTTL = git.DefaultCacheTTL
+
+	cmd := &cobra.Command{Use: "snippety", Run: func(cmd *cobra.Command, args []string) {}}
+	cmd.Flags().DurationVar(&cacheTTL, "cache-ttl", cacheTTL, "")
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() unexpected error: %v", err)
+	}
+
+	if err := applyConfigCacheTTL(cmd); err != nil {
+		t.Fatalf("applyConfigCacheTTL() unexpected error: %v", err)
+	}
+	if cacheTTL != 12*time.Hour {
+		t.Errorf("cacheTTL = %v, want 12h from the config", cacheTTL)
+	}
+
+	cfg.Cache.TTL = "a day"
+	if err := applyConfigCacheTTL(cmd); err == nil {
+		t.Error("applyConfigCacheTTL() expected an error for an invalid duration")
+	}
+}
+
+func TestModelsToPull(t *testing.T) {
+	savedModel, savedEmbed, savedFallbacks := ollamaModel, embedModel, fallbacks
+	defer func() { ollamaModel, embedModel, fallbacks = savedModel, savedEmbed, savedFallbacks }()
+	ollamaModel, embedModel = "qwen2.5-coder:7b", "nomic-embed-text"
+	fallbacks = []string{"llama3.2:3b", "qwen2.5-coder:7b"}
+
+	if models := modelsToPull(rootCmd); !reflect.DeepEqual(models, []string{"qwen2.5-coder:7b", "llama3.2:3b"}) {
+		t.Errorf("modelsToPull(rootCmd) = %v, want the model and its fallbacks", models)
+	}
+	if models := modelsToPull(indexCmd); !reflect.DeepEqual(models, []string{"nomic-embed-text"}) {
+		t.Errorf("modelsToPull(indexCmd) = %v, want the embedding model", models)
+	}
+}
+
 // Test that we can create multiple command instances without conflicts
 func TestCommandIsolation(t *testing.T) {
 	// This test ensures our command can be instantiated multiple times
@@ -0,0 +1,30 @@
+package cobra
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+)
+
+var (
+	groupBy     string
+	splitDryRun bool
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split staged changes into multiple logical commits",
+	Long: `Groups the staged changes into logically related sets (by directory,
+package or model-assisted clustering), proposes a commit message for each
+set and on confirmation creates one commit per set. Model-assisted grouping
+can split the hunks of a modified file across commits.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		git.SplitCommits(ollamaURL, ollamaModel, fallbacks, tone, language, groupBy, autoStage, splitDryRun, commitOptions())
+	},
+}
+
+func init() {
+	splitCmd.Flags().StringVar(&groupBy, "group-by", git.GroupByPackage, "how to group changes (dir, package, model)")
+	splitCmd.Flags().BoolVar(&splitDryRun, "dry-run", false, "only show the proposed commits without creating them")
+	rootCmd.AddCommand(splitCmd)
+}
@@ -0,0 +1,30 @@
+package cobra
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+)
+
+var (
+	squashBase   string
+	squashCommit bool
+)
+
+var squashCmd = &cobra.Command{
+	Use:   "squash",
+	Short: "Generate a squash-merge message for the current branch",
+	Long: `Feeds the messages and combined diff of the commits since the merge-base
+to the model and produces one consolidated message with Co-authored-by
+trailers for every other author. With --commit the branch is soft reset to
+the merge-base and the squashed commit is created.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		git.SquashCommits(ollamaURL, ollamaModel, fallbacks, tone, language, squashBase, squashCommit, commitOptions())
+	},
+}
+
+func init() {
+	squashCmd.Flags().StringVar(&squashBase, "base", "", "base branch to squash against (auto-detected by default)")
+	squashCmd.Flags().BoolVar(&squashCommit, "commit", false, "soft reset to the merge-base and create the squashed commit")
+	rootCmd.AddCommand(squashCmd)
+}
@@ -0,0 +1,67 @@
+package cobra
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+	"github.com/tahcohcat/snippety/internal/config"
+)
+
+var tonesCmd = &cobra.Command{
+	Use:   "tones",
+	Short: "Manage commit message tones",
+	Long: `Tones are built in, added as tones/<name>.tmpl in the prompt directory or
+defined as presets under "tones" in the config file.`,
+}
+
+var tonesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in and custom tones",
+	Run: func(cmd *cobra.Command, args []string) {
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSOURCE\tDESCRIPTION")
+		for _, t := range ollama.ListTones() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, t.Source, t.Description)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	tonesCmd.AddCommand(tonesListCmd)
+	rootCmd.AddCommand(tonesCmd)
+}
+
+// tonePresets converts the tone presets from the config file, leaving out
+// invalid ones with a warning.
+func tonePresets(tones map[string]config.ToneConfig) []ollama.Tone {
+	names := make([]string, 0, len(tones))
+	for name := range tones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	presets := make([]ollama.Tone, 0, len(names))
+	for _, name := range names {
+		tc := tones[name]
+		preset := ollama.Tone{
+			Name:        name,
+			Description: tc.Description,
+			Instruction: tc.Instruction,
+			Examples:    tc.Examples,
+			Constraints: tc.Constraints,
+		}
+		if err := preset.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "%sWarning: %v%s\n", git.ColorYellow, err, git.ColorReset)
+			continue
+		}
+		presets = append(presets, preset)
+	}
+	return presets
+}
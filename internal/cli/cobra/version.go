@@ -0,0 +1,35 @@
+package cobra
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/tahcohcat/snippety/internal/cli/git"
+)
+
+var (
+	versionTag     bool
+	versionOffline bool
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Semantic version helpers",
+}
+
+var versionNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Suggest the next semantic version from commits",
+	Long: `Parses the Conventional Commits since the latest semver tag, computes the
+next major, minor or patch version and lists the commits that drove it.
+With --tag an annotated tag with a generated release message is created.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		git.NextVersion(ollamaURL, ollamaModel, fallbacks, versionTag, versionOffline)
+	},
+}
+
+func init() {
+	versionNextCmd.Flags().BoolVar(&versionTag, "tag", false, "create an annotated tag for the next version")
+	versionNextCmd.Flags().BoolVar(&versionOffline, "offline", false, "build the tag message from raw commit titles without calling the model")
+	versionCmd.AddCommand(versionNextCmd)
+	rootCmd.AddCommand(versionCmd)
+}
@@ -0,0 +1,261 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// emptyTree is the id of git's empty tree, used as the parent of root commits.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// AmendCommit regenerates the message for HEAD from its changes plus any
+// newly staged ones and amends the commit with it.
+func AmendCommit(ollamaURL, ollamaModel string, fallbackModels []string, tone, language string, dryRun bool, commitOpts CommitOptions) {
+	base := "HEAD~1"
+	if !hasParent("HEAD") {
+		base = emptyTree
+	}
+
+	// Diffing the index against HEAD's parent covers both HEAD and newly staged changes
+	cmd := exec.Command("git", "diff", "--staged", base)
+	output, err := cmd.Output()
+	if err != nil {
+		fmt.Printf("%sError getting diff for HEAD: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	commitMsg, ok := regenerateMessage(ollamaURL, ollamaModel, fallbackModels, tone, language, string(output), commitOpts)
+	if !ok || dryRun {
+		return
+	}
+
+	confirmed, err := confirm("\nDo you want to amend HEAD with this message? (y/N): ", os.Stdout)
+	if err != nil {
+		fmt.Printf("Error reading input: %v\n", err)
+		return
+	}
+	if !confirmed {
+		fmt.Println("Commit not amended.")
+		return
+	}
+
+	if err := createCommit(commitMsg, commitOpts, "--amend"); err != nil {
+		fmt.Printf("%sError amending commit: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	fmt.Printf("%s✅ Commit amended successfully!%s\n", ColorGreen, ColorReset)
+}
+
+// RewordCommit regenerates the message for rev and rewrites it in place. For
+// commits older than HEAD this runs a non-interactive autosquash rebase.
+func RewordCommit(ollamaURL, ollamaModel string, fallbackModels []string, tone, language, rev string, dryRun bool, commitOpts CommitOptions) {
+	sha, err := resolveRevision(rev)
+	if err != nil {
+		fmt.Printf("%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	head, err := resolveRevision("HEAD")
+	if err != nil {
+		fmt.Printf("%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	if err := exec.Command("git", "merge-base", "--is-ancestor", sha, head).Run(); err != nil {
+		fmt.Printf("%sCommit %s is not an ancestor of HEAD%s\n", ColorRed, rev, ColorReset)
+		return
+	}
+	if isMergeCommit(sha) {
+		fmt.Printf("%sRewording merge commits is not supported%s\n", ColorRed, ColorReset)
+		return
+	}
+	// The rebase would flatten merges between sha and HEAD
+	if hasMergesSince(sha) {
+		fmt.Printf("%sRewording commits older than a merge commit is not supported%s\n", ColorRed, ColorReset)
+		return
+	}
+
+	diff, err := getRevisionDiff(sha)
+	if err != nil {
+		fmt.Printf("%sError getting diff for %s: %v%s\n", ColorRed, rev, err, ColorReset)
+		return
+	}
+
+	commitMsg, ok := regenerateMessage(ollamaURL, ollamaModel, fallbackModels, tone, language, diff, commitOpts)
+	if !ok || dryRun {
+		return
+	}
+
+	confirmed, err := confirm(fmt.Sprintf("\nDo you want to reword %s with this message? (y/N): ", shortSHA(sha)), os.Stdout)
+	if err != nil {
+		fmt.Printf("Error reading input: %v\n", err)
+		return
+	}
+	if !confirmed {
+		fmt.Println("Commit not reworded.")
+		return
+	}
+
+	if sha == head {
+		// --only without paths leaves any staged changes out of the commit
+		err = createCommit(commitMsg, commitOpts, "--amend", "--only")
+	} else {
+		err = rewordWithRebase(sha, commitMsg, commitOpts)
+	}
+	if err != nil {
+		fmt.Printf("%sError rewording commit: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	fmt.Printf("%s✅ Commit reworded successfully!%s\n", ColorGreen, ColorReset)
+}
+
+// regenerateMessage generates and prints a commit message for diff in
+// language with the trailers requested by commitOpts. It reports false when there is nothing
+// to describe or the trailers are invalid.
+func regenerateMessage(ollamaURL, ollamaModel string, fallbackModels []string, tone, language, diff string, commitOpts CommitOptions) (ollama.CommitMessage, bool) {
+	if strings.TrimSpace(diff) == "" {
+		fmt.Printf("%sNo changes found in the commit.%s\n", ColorYellow, ColorReset)
+		return ollama.CommitMessage{}, false
+	}
+
+	trailers, err := commitOpts.trailers()
+	if err != nil {
+		fmt.Printf("%s%v%s\n", ColorRed, err, ColorReset)
+		return ollama.CommitMessage{}, false
+	}
+
+	logrus.
+		WithField("llm", "ollama").
+		WithField("url", ollamaURL).
+		WithField("model", ollamaModel).
+		Debug("regenerating commit message")
+
+	// Regenerating asks for a new message, so the message cache is bypassed
+	chain := newModelChain(ollamaURL, ollamaModel, fallbackModels)
+	ctx := context.Background()
+
+	ticketPrefix := currentTicketPrefix(os.Stdout)
+	chain.available(ctx)
+	pc := diffPromptContext(diff, ticketPrefix)
+	pc.Language = language
+	commitMsg, _ := generateMessage(chain, diff, tone, ticketPrefix, pc)
+	commitMsg.Trailers = mergeTrailers(commitMsg.Trailers, trailers)
+
+	printCommitMessage(commitMsg, os.Stdout)
+	return commitMsg, true
+}
+
+// rewordWithRebase records an "amend!" commit carrying the new message on
+// top of HEAD and lets an autosquash rebase fold it into sha. The rewritten
+// commits are signed as requested by opts.
+func rewordWithRebase(sha string, msg ollama.CommitMessage, opts CommitOptions) error {
+	subject, err := exec.Command("git", "log", "-1", "--format=%s", sha).Output()
+	if err != nil {
+		return fmt.Errorf("failed to read subject of %s: %w", shortSHA(sha), err)
+	}
+
+	head, err := resolveRevision("HEAD")
+	if err != nil {
+		return err
+	}
+
+	body, err := renderCommitMessage(msg, opts)
+	if err != nil {
+		return err
+	}
+	message := "amend! " + strings.TrimSpace(string(subject)) + "\n\n" + body
+
+	// commit-tree leaves the index and working tree untouched. The amend!
+	// commit is squashed away, so it is not worth signing.
+	cmd := exec.Command("git", "commit-tree", "HEAD^{tree}", "-p", "HEAD", "-F", "-")
+	cmd.Stdin = strings.NewReader(message)
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("git commit-tree failed: %w", err)
+	}
+	amendSHA := strings.TrimSpace(string(output))
+
+	if output, err := exec.Command("git", "update-ref", "-m", "snippety: reword "+shortSHA(sha), "HEAD", amendSHA).CombinedOutput(); err != nil {
+		return fmt.Errorf("git update-ref failed: %w\nOutput: %s", err, string(output))
+	}
+
+	args := []string{"rebase", "--interactive", "--autosquash", "--autostash"}
+	args = append(args, opts.signArgs()...)
+	if hasParent(sha) {
+		args = append(args, sha+"~1")
+	} else {
+		args = append(args, "--root")
+	}
+
+	cmd = exec.Command("git", args...)
+	// Accept the autosquashed todo list as is
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true", "GIT_EDITOR=true")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		abortRebase(head)
+		if signErr, ok := signingFailure(string(output)); ok {
+			return signErr
+		}
+		return fmt.Errorf("git rebase failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// abortRebase puts the branch back to head, dropping the amend! commit,
+// after a failed rebase.
+func abortRebase(head string) {
+	if rebaseInProgress() {
+		if output, err := exec.Command("git", "rebase", "--abort").CombinedOutput(); err != nil {
+			logrus.WithError(err).Warnf("could not abort rebase: %s", string(output))
+			return
+		}
+	}
+	if output, err := exec.Command("git", "update-ref", "-m", "snippety: abort reword", "HEAD", head).CombinedOutput(); err != nil {
+		logrus.WithError(err).Warnf("could not restore %s: %s", shortSHA(head), string(output))
+	}
+}
+
+func resolveRevision(rev string) (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown revision '%s'", rev)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func hasParent(rev string) bool {
+	return exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"~1").Run() == nil
+}
+
+// rebaseInProgress reports whether an interactive rebase has stopped.
+func rebaseInProgress() bool {
+	path, err := gitPath("rebase-merge")
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// hasMergesSince reports whether there are merge commits between rev and HEAD.
+func hasMergesSince(rev string) bool {
+	output, err := exec.Command("git", "rev-list", "--merges", rev+"..HEAD").Output()
+	return err == nil && strings.TrimSpace(string(output)) != ""
+}
+
+func isMergeCommit(rev string) bool {
+	return exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^2").Run() == nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,95 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+func TestRewordWithRebaseRestoresHeadOnFailure(t *testing.T) {
+	git := newTestRepo(t)
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		writeTestFile(t, name, name+"\n")
+		git("add", name)
+		git("commit", "-q", "-m", "add "+name)
+	}
+	head := strings.TrimSpace(git("rev-parse", "HEAD"))
+	target := strings.TrimSpace(git("rev-parse", "HEAD~1"))
+
+	// A failing hook stops the rebase after the amend! commit was recorded
+	hooks := strings.TrimSpace(git("rev-parse", "--git-path", "hooks"))
+	hook := filepath.Join(hooks, "pre-rebase")
+	if err := os.MkdirAll(hooks, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, hook, "#!/bin/sh\nexit 1\n")
+	if err := os.Chmod(hook, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := rewordWithRebase(target, ollama.CommitMessage{Title: "docs: add b"}, CommitOptions{})
+	if err == nil {
+		t.Fatal("rewordWithRebase() expected an error")
+	}
+	if result := strings.TrimSpace(git("rev-parse", "HEAD")); result != head {
+		t.Errorf("HEAD = %s after failed reword, want %s", result, head)
+	}
+	if rebaseInProgress() {
+		t.Error("rebase still in progress after failed reword")
+	}
+}
+
+func TestHasMergesSince(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "a.txt", "a\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "add a")
+	base := strings.TrimSpace(git("rev-parse", "HEAD"))
+
+	if hasMergesSince(base) {
+		t.Error("hasMergesSince() = true without any merges")
+	}
+
+	git("checkout", "-q", "-b", "feature")
+	writeTestFile(t, "b.txt", "b\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "add b")
+	git("checkout", "-q", "main")
+	writeTestFile(t, "c.txt", "c\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "add c")
+	git("merge", "-q", "--no-edit", "feature")
+
+	if !hasMergesSince(base) {
+		t.Error("hasMergesSince() = false across a merge")
+	}
+	if hasMergesSince("HEAD") {
+		t.Error("hasMergesSince(HEAD) = true")
+	}
+}
+
+func TestRewordWithRebaseAppliesTemplate(t *testing.T) {
+	git := newTestRepo(t)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		writeTestFile(t, name, name+"\n")
+		git("add", name)
+		git("commit", "-q", "-m", "add "+name)
+	}
+	writeTestFile(t, "template.txt", "# Describe the change\nReviewed-by: Platform Team <platform@example.com>\n")
+	git("config", "commit.template", "template.txt")
+
+	if err := rewordWithRebase(strings.TrimSpace(git("rev-parse", "HEAD~1")), ollama.CommitMessage{Title: "docs: add a"}, CommitOptions{}); err != nil {
+		t.Fatalf("rewordWithRebase() unexpected error: %v", err)
+	}
+
+	message := git("log", "-1", "--format=%B", "HEAD~1")
+	if !strings.HasPrefix(message, "docs: add a") || !strings.Contains(message, "Reviewed-by: Platform Team <platform@example.com>") {
+		t.Errorf("reworded message should follow commit.template, got:\n%s", message)
+	}
+}
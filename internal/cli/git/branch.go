@@ -0,0 +1,150 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// DefaultBranchPattern is used when neither --pattern nor the config sets one.
+const DefaultBranchPattern = "{type}/{TICKET}-{slug}"
+
+var (
+	ticketPattern       = regexp.MustCompile(`\b[A-Z]+-\d+\b`)
+	slugInvalidPattern  = regexp.MustCompile(`[^a-z0-9]+`)
+	separatorRunPattern = regexp.MustCompile(`([/_-])[/_-]+`)
+)
+
+var branchTypes = map[string]bool{
+	"feat":     true,
+	"fix":      true,
+	"docs":     true,
+	"refactor": true,
+	"test":     true,
+	"chore":    true,
+}
+
+// maxSlugWords keeps generated branch names short enough to type.
+const maxSlugWords = 5
+
+// SuggestBranchName proposes a branch name following pattern from a task
+// description or, when description is empty, from the staged diff. With
+// switchBranch the branch is created with 'git switch -c'. The models of the
+// chain are tried in order.
+func SuggestBranchName(ollamaURL, ollamaModel string, fallbackModels []string, description, pattern, ticket string, switchBranch bool) {
+	input := strings.TrimSpace(description)
+	fallbackText := input
+	if input == "" {
+		diff, err := getStagedDiff()
+		if err != nil {
+			fmt.Printf("%sError getting staged diff: %v%s\n", ColorRed, err, ColorReset)
+			return
+		}
+		if strings.TrimSpace(diff) == "" {
+			fmt.Printf("%sDescribe the task or stage some changes to name a branch after.%s\n", ColorYellow, ColorReset)
+			return
+		}
+		input = diff
+		fallbackText = analyzeAndGenerateMessage(diff, "")
+	}
+
+	if ticket == "" {
+		ticket = ticketPattern.FindString(description)
+	}
+
+	chain := newModelChain(ollamaURL, ollamaModel, fallbackModels)
+	ctx := context.Background()
+
+	suggestion := fallbackBranchSuggestion(fallbackText)
+	if chain.available(ctx) {
+		chain.run("branch name", func(client *ollama.Client) error {
+			generated, err := client.SuggestBranch(ctx, input)
+			if err == nil {
+				suggestion = generated
+			}
+			return err
+		})
+	}
+
+	if !branchTypes[suggestion.Type] {
+		suggestion.Type = branchTypeFromText(fallbackText)
+	}
+
+	name := renderBranchName(pattern, suggestion.Type, ticket, slugify(suggestion.Slug))
+	if err := exec.Command("git", "check-ref-format", "--branch", name).Run(); err != nil {
+		fmt.Printf("%s'%s' is not a valid branch name, check the pattern '%s'%s\n", ColorRed, name, pattern, ColorReset)
+		return
+	}
+
+	if ticket != "" && extractTicketPrefix(name) != ticket+": " {
+		fmt.Printf("%sWarning: Branch '%s' does not match ticket pattern, commit messages will not include ticket prefix%s\n", ColorYellow, name, ColorReset)
+	}
+
+	fmt.Printf("%sSuggested branch:%s %s%s%s\n", ColorBold+ColorBlue, ColorReset, ColorGreen, name, ColorReset)
+
+	if !switchBranch {
+		return
+	}
+
+	if output, err := exec.Command("git", "switch", "-c", name).CombinedOutput(); err != nil {
+		fmt.Printf("%sgit switch -c failed: %v\nOutput: %s%s\n", ColorRed, err, string(output), ColorReset)
+		return
+	}
+	fmt.Printf("%s✅ Switched to new branch '%s'%s\n", ColorGreen, name, ColorReset)
+}
+
+// renderBranchName fills in {type}, {TICKET} and {slug}. Without a ticket
+// the placeholder and the separator next to it are dropped.
+func renderBranchName(pattern, branchType, ticket, slug string) string {
+	name := strings.NewReplacer(
+		"{type}", branchType,
+		"{TICKET}", ticket,
+		"{slug}", slug,
+	).Replace(pattern)
+
+	name = separatorRunPattern.ReplaceAllString(name, "$1")
+	return strings.Trim(name, "/_-")
+}
+
+// slugify lowercases s and joins its first words with hyphens.
+func slugify(s string) string {
+	s = ticketPattern.ReplaceAllString(s, "")
+	words := strings.Fields(slugInvalidPattern.ReplaceAllString(strings.ToLower(s), " "))
+	if len(words) > maxSlugWords {
+		words = words[:maxSlugWords]
+	}
+	return strings.Join(words, "-")
+}
+
+func fallbackBranchSuggestion(text string) ollama.BranchSuggestion {
+	return ollama.BranchSuggestion{
+		Type: branchTypeFromText(text),
+		Slug: text,
+	}
+}
+
+// branchTypeFromText guesses the branch type from the leading verb.
+func branchTypeFromText(text string) string {
+	fields := strings.Fields(strings.ToLower(ticketPattern.ReplaceAllString(text, "")))
+	if len(fields) == 0 {
+		return "chore"
+	}
+
+	switch strings.Trim(fields[0], ":") {
+	case "fix", "fixes", "bug", "bugfix", "resolve", "repair", "correct":
+		return "fix"
+	case "add", "adds", "implement", "create", "introduce", "support", "allow", "enable", "feat", "feature":
+		return "feat"
+	case "doc", "docs", "document":
+		return "docs"
+	case "refactor", "clean", "cleanup", "simplify", "restructure", "rename":
+		return "refactor"
+	case "test", "tests":
+		return "test"
+	}
+	return "chore"
+}
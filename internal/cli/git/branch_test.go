@@ -0,0 +1,123 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderBranchName(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		branchType string
+		ticket     string
+		slug       string
+		expected   string
+	}{
+		{
+			name:       "Default pattern",
+			pattern:    DefaultBranchPattern,
+			branchType: "feat",
+			ticket:     "AUTH-12",
+			slug:       "add-login-form",
+			expected:   "feat/AUTH-12-add-login-form",
+		},
+		{
+			name:       "Default pattern without ticket",
+			pattern:    DefaultBranchPattern,
+			branchType: "fix",
+			slug:       "handle-nil-user",
+			expected:   "fix/handle-nil-user",
+		},
+		{
+			name:       "Ticket first pattern",
+			pattern:    "{TICKET}-{slug}",
+			branchType: "feat",
+			ticket:     "BP-3648",
+			slug:       "add-lux-hack",
+			expected:   "BP-3648-add-lux-hack",
+		},
+		{
+			name:       "Ticket first pattern without ticket",
+			pattern:    "{TICKET}-{slug}",
+			branchType: "feat",
+			slug:       "add-lux-hack",
+			expected:   "add-lux-hack",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := renderBranchName(tt.pattern, tt.branchType, tt.ticket, tt.slug)
+			if result != tt.expected {
+				t.Errorf("renderBranchName() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderBranchNameRoundTripsTicket(t *testing.T) {
+	for _, pattern := range []string{DefaultBranchPattern, "{TICKET}-{slug}", "users/{type}/{TICKET}_{slug}"} {
+		name := renderBranchName(pattern, "feat", "PROJ-456", "cleanup")
+		if result := extractTicketPrefix(name); result != "PROJ-456: " {
+			t.Errorf("extractTicketPrefix(%q) = %q, want %q", name, result, "PROJ-456: ")
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Add login form", "add-login-form"},
+		{"AUTH-12: Fix the crash when saving a very large file", "fix-the-crash-when-saving"},
+		{"add-login-form", "add-login-form"},
+		{"Enhance internal/api/server.go", "enhance-internal-api-server-go"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := slugify(tt.input); result != tt.expected {
+				t.Errorf("slugify(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBranchTypeFromText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"Fix crash on startup", "fix"},
+		{"AUTH-12 add login form", "feat"},
+		{"Document the release process", "docs"},
+		{"Refactor storage layer", "refactor"},
+		{"Bump dependencies", "chore"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if result := branchTypeFromText(tt.text); result != tt.expected {
+				t.Errorf("branchTypeFromText(%q) = %q, want %q", tt.text, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSuggestBranchNameUsesFallbackModel(t *testing.T) {
+	newTestRepo(t)
+
+	server := chainServer(t, []string{"llama3.2:3b"}, map[string]string{"llama3.2:3b": "TYPE: feat\nSLUG: export-reports"})
+	defer server.Close()
+
+	stdout, _ := captureOutput(t, func() {
+		SuggestBranchName(server.URL, "qwen2.5-coder:7b", []string{"llama3.2:3b"}, "Let users export reports", DefaultBranchPattern, "", false)
+	})
+
+	if !strings.Contains(stdout, "feat/export-reports") {
+		t.Errorf("the fallback model should name the branch, got:\n%s", stdout)
+	}
+}
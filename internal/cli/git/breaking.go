@@ -0,0 +1,265 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// func Name(, func (r *T) Name(
+	exportedFuncPattern = regexp.MustCompile(`^func\s+(\([^)]*\)\s*)?([A-Z]\w*)\s*[\[(]`)
+	// type Name ..., var Name ..., const Name ...
+	exportedDeclPattern = regexp.MustCompile(`^(type|var|const)\s+([A-Z]\w*)\b`)
+	// var (, const (, type (
+	declGroupPattern = regexp.MustCompile(`^(type|var|const)\s*\($`)
+	// Name ..., A, B = ... inside a grouped declaration
+	groupedDeclPattern = regexp.MustCompile(`^\t(\w+(?:\s*,\s*\w+)*)`)
+	// rootCmd.Flags().StringVar(&x, "name", ...), cmd.PersistentFlags().Bool("name", ...)
+	cliFlagPattern = regexp.MustCompile(`(?:Persistent)?Flags\(\)\.\w+\((?:&[\w.]+,\s*)?"([\w-]+)"`)
+	// `json:"key"`, `yaml:"key,omitempty"`, `mapstructure:"key"`
+	configTagPattern = regexp.MustCompile(`(?:json|yaml|toml|mapstructure):"([\w.-]+)`)
+	// top-level keys in yaml/toml/ini style config files
+	configKeyPattern = regexp.MustCompile(`^([\w.-]+)\s*[:=]`)
+	// top-level keys in json config files
+	jsonKeyPattern = regexp.MustCompile(`^\s{0,2}"([\w.-]+)"\s*:`)
+)
+
+var configFileExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".toml": true,
+	".json": true,
+	".ini":  true,
+	".env":  true,
+}
+
+// apiSurface collects the exported identifiers, CLI flags and config keys
+// found on one side (added or removed lines) of a diff.
+type apiSurface struct {
+	identifiers map[string]string // key -> declaration line
+	flags       map[string]bool
+	configKeys  map[string]bool
+}
+
+func newAPISurface() apiSurface {
+	return apiSurface{
+		identifiers: map[string]string{},
+		flags:       map[string]bool{},
+		configKeys:  map[string]bool{},
+	}
+}
+
+// detectBreakingChanges inspects a unified diff for backwards-incompatible
+// changes: removed or re-signatured exported Go identifiers, deleted CLI
+// flags and removed config keys. It returns one human readable note per change.
+func detectBreakingChanges(diff string) []string {
+	removed := newAPISurface()
+	added := newAPISurface()
+
+	var currentFile string
+	// inHeader is set between a "diff --git" line and the file's first hunk
+	var inHeader bool
+	// oldGroup and newGroup are the keywords of the grouped declaration
+	// each side of the diff is inside, if any
+	var oldGroup, newGroup string
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			currentFile = diffPath(line)
+			inHeader = true
+			oldGroup, newGroup = "", ""
+		case strings.HasPrefix(line, "@@"):
+			inHeader = false
+			// git names the enclosing "var (" in the hunk header when the
+			// hunk starts inside a grouped declaration
+			oldGroup = hunkDeclGroup(line)
+			newGroup = oldGroup
+		case inHeader:
+			// file headers, including the "---" and "+++" lines
+			if path, ok := headerPath(line); ok {
+				currentFile = path
+			}
+		case strings.HasPrefix(line, "-"):
+			collectAPISurface(removed, currentFile, oldGroup, line[1:])
+			oldGroup = nextDeclGroup(oldGroup, line[1:])
+		case strings.HasPrefix(line, "+"):
+			collectAPISurface(added, currentFile, newGroup, line[1:])
+			newGroup = nextDeclGroup(newGroup, line[1:])
+		case strings.HasPrefix(line, " "):
+			oldGroup = nextDeclGroup(oldGroup, line[1:])
+			newGroup = nextDeclGroup(newGroup, line[1:])
+		}
+	}
+
+	var changes []string
+
+	for _, key := range sortedKeys(removed.identifiers) {
+		oldDecl := removed.identifiers[key]
+		newDecl, ok := added.identifiers[key]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("removed exported %s", key))
+		case normalizeDecl(oldDecl) != normalizeDecl(newDecl):
+			changes = append(changes, fmt.Sprintf("changed signature of %s", key))
+		}
+	}
+
+	for _, flag := range sortedKeys(removed.flags) {
+		if !added.flags[flag] {
+			changes = append(changes, fmt.Sprintf("removed CLI flag --%s", flag))
+		}
+	}
+
+	for _, key := range sortedKeys(removed.configKeys) {
+		if !added.configKeys[key] {
+			changes = append(changes, fmt.Sprintf("removed config key %q", key))
+		}
+	}
+
+	return changes
+}
+
+// collectAPISurface records the API found on one diff line of fileName.
+// group is the keyword of the grouped declaration the line is inside, if any.
+func collectAPISurface(surface apiSurface, fileName, group, content string) {
+	if strings.HasSuffix(fileName, ".go") {
+		if strings.HasSuffix(fileName, "_test.go") {
+			return
+		}
+
+		trimmed := strings.TrimSpace(content)
+		// Only top-level declarations start at column zero in gofmt'd code
+		if content == trimmed {
+			if matches := exportedFuncPattern.FindStringSubmatch(trimmed); matches != nil {
+				name := matches[2]
+				receiver := receiverType(matches[1])
+				if receiver != "" {
+					name = receiver + "." + name
+				}
+				// Methods of unexported types cannot be called from outside the package
+				if receiver == "" || isExported(receiver) {
+					surface.identifiers["func "+name] = trimmed
+				}
+			} else if matches := exportedDeclPattern.FindStringSubmatch(trimmed); matches != nil {
+				surface.identifiers[matches[1]+" "+matches[2]] = trimmed
+			}
+		} else if group != "" {
+			// Grouped declarations are indented by exactly one tab
+			if matches := groupedDeclPattern.FindStringSubmatch(content); matches != nil {
+				for _, name := range strings.Split(matches[1], ",") {
+					name = strings.TrimSpace(name)
+					if isExported(name) {
+						surface.identifiers[group+" "+name] = group + " " + trimmed
+					}
+				}
+			}
+		}
+
+		for _, matches := range cliFlagPattern.FindAllStringSubmatch(content, -1) {
+			surface.flags[matches[1]] = true
+		}
+		for _, matches := range configTagPattern.FindAllStringSubmatch(content, -1) {
+			if matches[1] != "-" {
+				surface.configKeys[matches[1]] = true
+			}
+		}
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if !configFileExtensions[ext] {
+		return
+	}
+
+	if ext == ".json" {
+		if matches := jsonKeyPattern.FindStringSubmatch(content); matches != nil {
+			surface.configKeys[matches[1]] = true
+		}
+		return
+	}
+
+	if matches := configKeyPattern.FindStringSubmatch(content); matches != nil && !strings.HasPrefix(content, "#") {
+		surface.configKeys[matches[1]] = true
+	}
+}
+
+// nextDeclGroup returns the grouped declaration keyword in effect after
+// content, given the one in effect before it.
+func nextDeclGroup(group, content string) string {
+	if matches := declGroupPattern.FindStringSubmatch(strings.TrimRight(content, " \t")); matches != nil {
+		return matches[1]
+	}
+	if content == ")" {
+		return ""
+	}
+	return group
+}
+
+// hunkDeclGroup returns the keyword of a grouped declaration named in the
+// function context of a "@@ -1 +1 @@ var (" hunk header.
+func hunkDeclGroup(header string) string {
+	idx := strings.LastIndex(header, "@@")
+	if idx <= 0 {
+		return ""
+	}
+	return nextDeclGroup("", strings.TrimSpace(header[idx+len("@@"):]))
+}
+
+func isExported(name string) bool {
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
+
+// receiverType reduces a method receiver such as "(c *Client) " to "Client".
+func receiverType(receiver string) string {
+	receiver = strings.Trim(strings.TrimSpace(receiver), "()")
+	// Type parameters such as [K, V] may contain spaces
+	if idx := strings.Index(receiver, "["); idx >= 0 {
+		receiver = receiver[:idx]
+	}
+	fields := strings.Fields(receiver)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(fields[len(fields)-1], "*")
+}
+
+// normalizeDecl strips the body and whitespace differences from a
+// declaration line so that only signature changes are compared. Variables
+// and constants keep their name and declared type, a new value is not a
+// breaking change.
+func normalizeDecl(decl string) string {
+	decl = strings.TrimSpace(decl)
+	if strings.HasPrefix(decl, "var ") || strings.HasPrefix(decl, "const ") {
+		decl, _, _ = strings.Cut(decl, "=")
+		decl, _, _ = strings.Cut(decl, "//")
+	}
+	decl = strings.TrimSuffix(decl, "{")
+	return strings.Join(strings.Fields(decl), " ")
+}
+
+// markBreakingTitle adds the Conventional Commits "!" marker to a
+// "type(scope): subject" title. Titles not in that format are returned as is.
+func markBreakingTitle(title string) string {
+	matches := conventionalHeaderPattern.FindStringSubmatchIndex(title)
+	if matches == nil {
+		return title
+	}
+	// group 4 is the optional "!" marker
+	if matches[8] >= 0 {
+		return title
+	}
+	colon := matches[1] - len(": ")
+	return title[:colon] + "!" + title[colon:]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,242 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectBreakingChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		expected []string
+	}{
+		{
+			name: "Removed exported function",
+			diff: `diff --git a/pkg/api.go b/pkg/api.go
+index 1234567..7890abc 100644
+--- a/pkg/api.go
++++ b/pkg/api.go
+@@ -1,7 +1,3 @@
+ package pkg
+
+-func Exported(a string) error {
+-	return nil
+-}`,
+			expected: []string{"removed exported func Exported"},
+		},
+		{
+			name: "Changed method signature",
+			diff: `diff --git a/pkg/client.go b/pkg/client.go
+index 1234567..7890abc 100644
+--- a/pkg/client.go
++++ b/pkg/client.go
+@@ -1,3 +1,3 @@
+-func (c *Client) Do(ctx context.Context) error {
++func (c *Client) Do(ctx context.Context, retries int) error {`,
+			expected: []string{"changed signature of func Client.Do"},
+		},
+		{
+			name: "Moved function is not breaking",
+			diff: `diff --git a/pkg/client.go b/pkg/client.go
+index 1234567..7890abc 100644
+--- a/pkg/client.go
++++ b/pkg/client.go
+@@ -1,3 +1,3 @@
+-func New(url string) *Client {
++func New(url string) *Client  {`,
+			expected: nil,
+		},
+		{
+			name: "Unexported and test identifiers ignored",
+			diff: `diff --git a/pkg/client.go b/pkg/client.go
+index 1234567..7890abc 100644
+--- a/pkg/client.go
++++ b/pkg/client.go
+@@ -1,3 +1,1 @@
+-func helper() {}
+diff --git a/pkg/client_test.go b/pkg/client_test.go
+index 1234567..7890abc 100644
+--- a/pkg/client_test.go
++++ b/pkg/client_test.go
+@@ -1,3 +1,1 @@
+-func TestHelper(t *testing.T) {}`,
+			expected: nil,
+		},
+		{
+			name: "Removed type and CLI flag",
+			diff: `diff --git a/cmd/root.go b/cmd/root.go
+index 1234567..7890abc 100644
+--- a/cmd/root.go
++++ b/cmd/root.go
+@@ -1,4 +1,2 @@
+-type Options struct {
+-	rootCmd.Flags().BoolVar(&verbose, "verbose", false, "verbose output")
+ 	rootCmd.Flags().BoolVar(&debug, "debug", false, "enable debug mode")`,
+			expected: []string{"removed exported type Options", "removed CLI flag --verbose"},
+		},
+		{
+			name: "Flag help text change is not breaking",
+			diff: `diff --git a/cmd/root.go b/cmd/root.go
+index 1234567..7890abc 100644
+--- a/cmd/root.go
++++ b/cmd/root.go
+@@ -1,1 +1,1 @@
+-	rootCmd.Flags().BoolVar(&debug, "debug", false, "debug")
++	rootCmd.Flags().BoolVar(&debug, "debug", false, "enable debug mode")`,
+			expected: nil,
+		},
+		{
+			name: "Removed config key from yaml and struct tag",
+			diff: `diff --git a/config.yaml b/config.yaml
+index 1234567..7890abc 100644
+--- a/config.yaml
++++ b/config.yaml
+@@ -1,3 +1,2 @@
+ model: llama3.2
+-timeout: 60
+diff --git a/internal/config/config.go b/internal/config/config.go
+index 1234567..7890abc 100644
+--- a/internal/config/config.go
++++ b/internal/config/config.go
+@@ -1,3 +1,2 @@
+-	Retries int ` + "`json:\"retries\"`" + `
++	Attempts int ` + "`json:\"attempts\"`",
+			expected: []string{`removed config key "retries"`, `removed config key "timeout"`},
+		},
+		{
+			name: "Removed constants from grouped declaration",
+			diff: `diff --git a/pkg/level.go b/pkg/level.go
+index 1234567..7890abc 100644
+--- a/pkg/level.go
++++ b/pkg/level.go
+@@ -1,6 +1,4 @@
+ const (
+ 	LevelInfo = iota
+-	LevelDebug
+-	levelTrace
+ )`,
+			expected: []string{"removed exported const LevelDebug"},
+		},
+		{
+			name: "Changed var in grouped declaration named by hunk header",
+			diff: `diff --git a/pkg/vars.go b/pkg/vars.go
+index 1234567..7890abc 100644
+--- a/pkg/vars.go
++++ b/pkg/vars.go
+@@ -10,3 +10,3 @@ var (
+ 	ErrClosed = errors.New("closed")
+-	Timeout, Retries int
++	Timeout, Retries int64
+ )`,
+			expected: []string{"changed signature of var Retries", "changed signature of var Timeout"},
+		},
+		{
+			name: "Realigned grouped declaration is not breaking",
+			diff: `diff --git a/pkg/vars.go b/pkg/vars.go
+index 1234567..7890abc 100644
+--- a/pkg/vars.go
++++ b/pkg/vars.go
+@@ -1,4 +1,5 @@
+ var (
+-	Name = "x"
++	Name      = "x"
++	LongerOne = "y"
+ )`,
+			expected: nil,
+		},
+		{
+			name: "Removed methods on unexported receivers are not API",
+			diff: `diff --git a/pkg/cache.go b/pkg/cache.go
+index 1234567..7890abc 100644
+--- a/pkg/cache.go
++++ b/pkg/cache.go
+@@ -1,6 +1,1 @@
+-func (c *cache[K, V]) Get(key K) V {
+-	return c.items[key]
+-}
+-func (client) Close() error {
+-	return nil
+-}`,
+			expected: nil,
+		},
+		{
+			name: "New values of constants and variables are not breaking",
+			diff: `diff --git a/pkg/version.go b/pkg/version.go
+index 1234567..7890abc 100644
+--- a/pkg/version.go
++++ b/pkg/version.go
+@@ -1,6 +1,6 @@
+-const Version = "1.0.0"
+-var DefaultTimeout = 10 * time.Second
+-var MaxRetries int = 3 // attempts
++const Version = "1.1.0"
++var DefaultTimeout = 20 * time.Second
++var MaxRetries int = 5
+ var (
+-	Name = "x"
++	Name = "y"
+ )`,
+			expected: nil,
+		},
+		{
+			name: "Changed type of a variable with a value",
+			diff: `diff --git a/pkg/version.go b/pkg/version.go
+index 1234567..7890abc 100644
+--- a/pkg/version.go
++++ b/pkg/version.go
+@@ -1,1 +1,1 @@
+-var MaxRetries int = 3
++var MaxRetries int64 = 3`,
+			expected: []string{"changed signature of var MaxRetries"},
+		},
+		{
+			name: "Removed lines starting with dashes are not file headers",
+			diff: `diff --git a/tool.toml b/tool.toml
+index 1234567..7890abc 100644
+--- a/tool.toml
++++ b/tool.toml
+@@ -1,3 +1,1 @@
+---legacy-mode = true
+-timeout = 60
+ model = "llama3.2"`,
+			expected: []string{`removed config key "--legacy-mode"`, `removed config key "timeout"`},
+		},
+		{
+			name:     "Empty diff",
+			diff:     "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := detectBreakingChanges(tt.diff)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("detectBreakingChanges() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMarkBreakingTitle(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"feat: add login", "feat!: add login"},
+		{"fix(api): handle nil client", "fix(api)!: handle nil client"},
+		{"refactor!: drop legacy flags", "refactor!: drop legacy flags"},
+		{"Update main.go", "Update main.go"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			result := markBreakingTitle(tt.title)
+			if result != tt.expected {
+				t.Errorf("markBreakingTitle(%q) = %q, want %q", tt.title, result, tt.expected)
+			}
+		})
+	}
+}
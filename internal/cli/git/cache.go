@@ -0,0 +1,160 @@
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// DefaultCacheTTL is how long a generated message is reused for the same
+// diff and prompt.
+const DefaultCacheTTL = 24 * time.Hour
+
+// messageCache stores generated commit messages on disk, keyed by the model
+// and the prompt they were generated from.
+type messageCache struct {
+	dir string
+	// ttl is how long entries are used, zero keeps them until cleared
+	ttl time.Duration
+}
+
+// cachedMessage is one cache entry.
+type cachedMessage struct {
+	Created     time.Time `json:"created"`
+	Model       string    `json:"model"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+}
+
+// messageCacheDir returns the cache location under the user cache directory.
+func messageCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	return filepath.Join(dir, "snippety", "messages"), nil
+}
+
+// newMessageCache opens the message cache, returning nil when there is no
+// cache directory.
+func newMessageCache(ttl time.Duration) *messageCache {
+	dir, err := messageCacheDir()
+	if err != nil {
+		logrus.WithError(err).Debug("message cache disabled")
+		return nil
+	}
+	return &messageCache{dir: dir, ttl: ttl}
+}
+
+func (c *messageCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// get returns the message cached under key, removing it when it expired.
+func (c *messageCache) get(key string) (ollama.CommitMessage, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return ollama.CommitMessage{}, false
+	}
+
+	var entry cachedMessage
+	if err := json.Unmarshal(data, &entry); err != nil {
+		logrus.WithError(err).Debug("ignoring unreadable cache entry")
+		return ollama.CommitMessage{}, false
+	}
+	if c.ttl > 0 && time.Since(entry.Created) > c.ttl {
+		os.Remove(c.path(key))
+		return ollama.CommitMessage{}, false
+	}
+	return ollama.CommitMessage{Title: entry.Title, Description: entry.Description}, true
+}
+
+// put stores msg under key. Failing to write only costs a regeneration, so
+// errors are logged.
+func (c *messageCache) put(key, model string, msg ollama.CommitMessage) {
+	data, err := json.MarshalIndent(cachedMessage{
+		Created:     time.Now(),
+		Model:       model,
+		Title:       msg.Title,
+		Description: msg.Description,
+	}, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(c.dir, 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(c.path(key), data, 0o644)
+	}
+	if err != nil {
+		logrus.WithError(err).Warn("could not cache commit message")
+	}
+}
+
+// messageCacheKey hashes everything that shapes the model's answer: the
+// model and its options, the tone and the prompt rendered from the current
+// templates with the normalized diff.
+func messageCacheKey(model, diff, tone string, pc ollama.PromptContext) (string, error) {
+	prompt, err := ollama.RenderCommitPrompt(normalizeDiff(diff), tone, pc)
+	if err != nil {
+		return "", err
+	}
+	options, err := json.Marshal(ollama.NewClient("", model).Options)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, part := range []string{model, string(options), tone, prompt} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// normalizeDiff drops what does not change the meaning of a diff: blob ids,
+// carriage returns and trailing whitespace.
+func normalizeDiff(diff string) string {
+	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+	normalized := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.HasPrefix(line, "index ") {
+			continue
+		}
+		normalized = append(normalized, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(normalized, "\n"))
+}
+
+// ClearMessageCache removes every cached commit message, reporting to w.
+func ClearMessageCache(w io.Writer) {
+	dir, err := messageCacheDir()
+	if err != nil {
+		fmt.Fprintf(w, "%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(w, "The message cache is empty.")
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(w, "%sError reading the message cache: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		fmt.Fprintf(w, "%sError clearing the message cache: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	fmt.Fprintf(w, "%s✅ Removed %d cached messages from %s%s\n", ColorGreen, len(entries), dir, ColorReset)
+}
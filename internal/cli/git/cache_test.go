@@ -0,0 +1,129 @@
+package git
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+func TestMessageCache(t *testing.T) {
+	cache := &messageCache{dir: t.TempDir(), ttl: time.Hour}
+	msg := ollama.CommitMessage{Title: "feat: add login", Description: "Adds a login form."}
+
+	if _, ok := cache.get("key"); ok {
+		t.Fatal("get() found an entry in an empty cache")
+	}
+	cache.put("key", "llama3.2", msg)
+	cached, ok := cache.get("key")
+	if !ok || !reflect.DeepEqual(cached, msg) {
+		t.Errorf("get() = %+v, %v, want %+v", cached, ok, msg)
+	}
+
+	// Age the entry past the TTL
+	expired := &messageCache{dir: cache.dir, ttl: time.Nanosecond}
+	time.Sleep(time.Millisecond)
+	if _, ok := expired.get("key"); ok {
+		t.Error("get() returned an expired entry")
+	}
+	if _, err := os.Stat(filepath.Join(cache.dir, "key.json")); !os.IsNotExist(err) {
+		t.Errorf("expired entry was not removed: %v", err)
+	}
+}
+
+func TestMessageCacheKey(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\nindex 1234567..89abcde 100644\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-old\n+new\n"
+	rebased := "diff --git a/a.go b/a.go\r\nindex 7654321..edcba98 100644\r\n--- a/a.go\r\n+++ b/a.go\r\n@@ -1 +1 @@\r\n-old  \r\n+new\r\n"
+
+	key := func(model, diff, tone string) string {
+		k, err := messageCacheKey(model, diff, tone, ollama.PromptContext{})
+		if err != nil {
+			t.Fatalf("messageCacheKey() unexpected error: %v", err)
+		}
+		return k
+	}
+
+	base := key("llama3.2", diff, "professional")
+	if key("llama3.2", rebased, "professional") != base {
+		t.Error("messageCacheKey() differs for diffs that only differ in blob ids and whitespace")
+	}
+	if key("mistral", diff, "professional") == base {
+		t.Error("messageCacheKey() does not depend on the model")
+	}
+	if key("llama3.2", diff, "pirate") == base {
+		t.Error("messageCacheKey() does not depend on the tone")
+	}
+	if key("llama3.2", diff+"+more\n", "professional") == base {
+		t.Error("messageCacheKey() does not depend on the diff")
+	}
+}
+
+func TestModelChainCache(t *testing.T) {
+	server := chainServer(t, []string{"llama3.2:latest"}, map[string]string{"llama3.2": "TITLE: feat: generated"})
+	defer server.Close()
+
+	chain := newModelChain(server.URL, "llama3.2", nil)
+	chain.cache = &messageCache{dir: t.TempDir(), ttl: time.Hour}
+	chain.available(t.Context())
+
+	first, link := chain.generate("diff", "professional", ollama.PromptContext{})
+	if link.Cached || first.Title != "feat: generated" {
+		t.Fatalf("generate() = %q, %+v, want a generated message", first.Title, link)
+	}
+
+	// With the server gone the message can only come from the cache
+	server.Close()
+	second, link := chain.generate("diff", "professional", ollama.PromptContext{})
+	if !link.Cached || link.Model != "llama3.2" || second.Title != first.Title {
+		t.Errorf("generate() = %q, %+v, want the cached message", second.Title, link)
+	}
+}
+
+func TestModelChainCacheNeedsInstalledModel(t *testing.T) {
+	server := chainServer(t, []string{"llama3.2:latest"}, map[string]string{"llama3.2": "TITLE: feat: generated"})
+	cache := &messageCache{dir: t.TempDir(), ttl: time.Hour}
+
+	chain := newModelChain(server.URL, "llama3.2", nil)
+	chain.cache = cache
+	chain.available(t.Context())
+	if _, link := chain.generate("diff", "professional", ollama.PromptContext{}); link.Model != "llama3.2" {
+		t.Fatalf("generate() link = %+v, want a generated message", link)
+	}
+	server.Close()
+
+	// A new run finds Ollama down and must not reuse the message
+	chain = newModelChain(server.URL, "llama3.2", nil)
+	chain.cache = cache
+	chain.out = io.Discard
+	chain.available(t.Context())
+	if _, link := chain.generate("diff", "professional", ollama.PromptContext{}); link.Cached || !link.offline() {
+		t.Errorf("generate() link = %+v, want the offline fallback", link)
+	}
+}
+
+func TestClearMessageCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir, err := messageCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := &messageCache{dir: dir, ttl: time.Hour}
+	cache.put("key", "llama3.2", ollama.CommitMessage{Title: "feat: cached"})
+
+	var out strings.Builder
+	stdout, _ := captureOutput(t, func() { ClearMessageCache(&out) })
+	if stdout != "" {
+		t.Errorf("ClearMessageCache() wrote %q to stdout", stdout)
+	}
+	if !strings.Contains(out.String(), "Removed 1 cached messages") {
+		t.Errorf("ClearMessageCache() reported %q", out.String())
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("ClearMessageCache() left %s behind: %v", dir, err)
+	}
+}
@@ -0,0 +1,158 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// offlineModel names the rule-based fallback that ends every model chain.
+const offlineModel = "offline"
+
+// modelChain is the ordered list of Ollama models tried for generation
+// before falling back to rule-based analysis.
+type modelChain struct {
+	url    string
+	models []string
+	// installed are the models of the chain found on the server by available
+	installed []string
+	// cache holds earlier messages, nil generates every message afresh
+	cache *messageCache
+	// regenerate skips the cached messages but replaces them with the new ones
+	regenerate bool
+	// out receives progress and warnings, stdout by default
+	out io.Writer
+}
+
+// chainLink records which link of a model chain produced a message.
+type chainLink struct {
+	// Index is the 1-based position in the chain, the offline fallback is last
+	Index int
+	Model string
+	// Cached is set when the message was generated by an earlier run
+	Cached bool
+}
+
+func (l chainLink) offline() bool {
+	return l.Model == offlineModel
+}
+
+// newModelChain builds the chain model, fallbacks..., dropping duplicates.
+func newModelChain(url, model string, fallbacks []string) *modelChain {
+	chain := &modelChain{url: url, out: os.Stdout}
+	for _, m := range append([]string{model}, fallbacks...) {
+		if m != "" && !slices.Contains(chain.models, m) {
+			chain.models = append(chain.models, m)
+		}
+	}
+	if len(chain.models) == 0 {
+		// NewClient picks the default model
+		chain.models = []string{ollama.NewClient(url, "").Model}
+	}
+	return chain
+}
+
+// links lists the models of the chain followed by the offline fallback.
+func (c *modelChain) links() []string {
+	return append(slices.Clone(c.models), offlineModel)
+}
+
+// available checks which models of the chain are installed and reports
+// whether any of them can be used.
+func (c *modelChain) available(ctx context.Context) bool {
+	c.installed = nil
+	for _, model := range c.models {
+		err := ollama.NewClient(c.url, model).HealthCheck(ctx)
+		if err == nil {
+			c.installed = append(c.installed, model)
+			continue
+		}
+		fmt.Fprintf(c.out, "Ollama health check failed: %v\n", err)
+		if !ollama.IsModelNotFound(err) {
+			// The server is unreachable, the other models will not do better
+			break
+		}
+	}
+
+	if len(c.installed) == 0 {
+		fmt.Fprintln(c.out, "Falling back to basic analysis...")
+		return false
+	}
+	return true
+}
+
+// client returns a client for the first installed model of the chain, or
+// for the first model when none is installed.
+func (c *modelChain) client() *ollama.Client {
+	if len(c.installed) > 0 {
+		return ollama.NewClient(c.url, c.installed[0])
+	}
+	return ollama.NewClient(c.url, c.models[0])
+}
+
+// generate asks the installed models in chain order, moving on when one
+// fails, times out or returns a response without a title, and falls back to
+// rule-based analysis when none succeeds. A cached message of an installed
+// model is used in place of asking it, unless the chain regenerates.
+func (c *modelChain) generate(diff, tone string, pc ollama.PromptContext) (ollama.CommitMessage, chainLink) {
+	for i, model := range c.models {
+		if !slices.Contains(c.installed, model) {
+			continue
+		}
+
+		key := ""
+		if c.cache != nil {
+			var err error
+			if key, err = messageCacheKey(model, diff, tone, pc); err != nil {
+				logrus.WithError(err).Debug("not caching commit message")
+			} else if !c.regenerate {
+				if cached, ok := c.cache.get(key); ok {
+					fmt.Fprintf(c.out, "Using the message %s generated earlier, pass --no-cache or answer 'r' for a new one\n", model)
+					return cached, chainLink{Index: i + 1, Model: model, Cached: true}
+				}
+			}
+		}
+
+		generated, err := ollama.NewClient(c.url, model).GenerateCommitMessage(context.Background(), diff, tone, pc)
+		if err == nil {
+			if key != "" {
+				c.cache.put(key, model, generated)
+			}
+			return generated, chainLink{Index: i + 1, Model: model}
+		}
+		fmt.Fprintf(c.out, "Error generating commit message with %s: %v\n", model, err)
+	}
+
+	if len(c.installed) > 0 {
+		fmt.Fprintln(c.out, "Falling back to basic analysis...")
+	}
+	return fallbackCommitMessage(diff, pc.Language), chainLink{Index: len(c.models) + 1, Model: offlineModel}
+}
+
+// run calls fn with a client for each installed model in chain order until
+// one succeeds, reporting what failed to produce with every model that does
+// not. It returns the link that succeeded, which is the offline fallback
+// when none did.
+func (c *modelChain) run(what string, fn func(client *ollama.Client) error) chainLink {
+	for i, model := range c.models {
+		if !slices.Contains(c.installed, model) {
+			continue
+		}
+		err := fn(ollama.NewClient(c.url, model))
+		if err == nil {
+			return chainLink{Index: i + 1, Model: model}
+		}
+		fmt.Fprintf(c.out, "Error generating %s with %s: %v\n", what, model, err)
+	}
+
+	if len(c.installed) > 0 {
+		fmt.Fprintln(c.out, "Falling back to basic analysis...")
+	}
+	return chainLink{Index: len(c.models) + 1, Model: offlineModel}
+}
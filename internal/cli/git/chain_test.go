@@ -0,0 +1,130 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// chainServer has the installed models and answers generate requests with
+// the response for the requested model, failing for models without one.
+func chainServer(t *testing.T, installed []string, responses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			var tags ollama.TagsResponse
+			for _, name := range installed {
+				tags.Models = append(tags.Models, ollama.ModelInfo{Name: name})
+			}
+			json.NewEncoder(w).Encode(tags)
+		case "/api/generate":
+			var req ollama.GenerateRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("failed to decode request: %v", err)
+			}
+			response, ok := responses[req.Model]
+			if !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			json.NewEncoder(w).Encode(ollama.GenerateResponse{Response: response, Done: true})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestModelChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []string
+		responses map[string]string
+		expected  chainLink
+		title     string
+	}{
+		{
+			name:      "First model answers",
+			installed: []string{"qwen2.5-coder:7b", "llama3.2:3b"},
+			responses: map[string]string{"qwen2.5-coder:7b": "TITLE: feat: first", "llama3.2:3b": "TITLE: feat: second"},
+			expected:  chainLink{Index: 1, Model: "qwen2.5-coder:7b"},
+			title:     "feat: first",
+		},
+		{
+			name:      "First model missing",
+			installed: []string{"llama3.2:3b"},
+			responses: map[string]string{"llama3.2:3b": "TITLE: feat: second"},
+			expected:  chainLink{Index: 2, Model: "llama3.2:3b"},
+			title:     "feat: second",
+		},
+		{
+			name:      "First model fails",
+			installed: []string{"qwen2.5-coder:7b", "llama3.2:3b"},
+			responses: map[string]string{"llama3.2:3b": "TITLE: feat: second"},
+			expected:  chainLink{Index: 2, Model: "llama3.2:3b"},
+			title:     "feat: second",
+		},
+		{
+			name:      "First model response unparseable",
+			installed: []string{"qwen2.5-coder:7b", "llama3.2:3b"},
+			responses: map[string]string{"qwen2.5-coder:7b": "   ", "llama3.2:3b": "TITLE: feat: second"},
+			expected:  chainLink{Index: 2, Model: "llama3.2:3b"},
+			title:     "feat: second",
+		},
+		{
+			name:      "Every model fails",
+			installed: []string{"qwen2.5-coder:7b"},
+			expected:  chainLink{Index: 3, Model: offlineModel},
+			title:     "Update project files",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := chainServer(t, tt.installed, tt.responses)
+			defer server.Close()
+
+			chain := newModelChain(server.URL, "qwen2.5-coder:7b", []string{"llama3.2:3b", "qwen2.5-coder:7b"})
+			chain.available(t.Context())
+			msg, link := chain.generate("", "professional", ollama.PromptContext{})
+			if link != tt.expected {
+				t.Errorf("generate() link = %+v, want %+v", link, tt.expected)
+			}
+			if msg.Title != tt.title {
+				t.Errorf("generate() title = %q, want %q", msg.Title, tt.title)
+			}
+		})
+	}
+}
+
+func TestWriteMessageJSON(t *testing.T) {
+	chain := newModelChain("http://localhost:11434", "qwen2.5-coder:7b", []string{"llama3.2:3b"})
+	msg := ollama.CommitMessage{Title: "feat: add login", Description: "Adds a login form."}
+
+	var out bytes.Buffer
+	if err := writeMessageJSON(&out, msg, 72, chain, chainLink{Index: 2, Model: "llama3.2:3b"}); err != nil {
+		t.Fatalf("writeMessageJSON() unexpected error: %v", err)
+	}
+
+	var result messageJSON
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("writeMessageJSON() wrote invalid JSON: %v", err)
+	}
+	expected := messageJSON{
+		Title:       "feat: add login",
+		Description: "Adds a login form.",
+		Message:     "feat: add login\n\nAdds a login form.\n",
+		Source: sourceJSON{
+			Link:  2,
+			Model: "llama3.2:3b",
+			Chain: []string{"qwen2.5-coder:7b", "llama3.2:3b", offlineModel},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("writeMessageJSON() = %+v, want %+v", result, expected)
+	}
+}
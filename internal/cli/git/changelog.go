@@ -0,0 +1,375 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// changelogDiffChars limits how much of each commit's diff the model sees.
+const changelogDiffChars = 2000
+
+// changelogBatchChars limits the entries and details sent in one request, so
+// that long release ranges stay within the model's context window.
+const changelogBatchChars = 16000
+
+// "[unreleased]: https://github.com/o/r/compare/v1.0.0...HEAD"
+var changelogLinkPattern = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+
+// Keep a Changelog sections in the order they are rendered
+var changelogSections = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// changelogEntry is a single bullet point of a changelog section.
+type changelogEntry struct {
+	Section  string
+	Text     string
+	Breaking bool
+	// Detail is context for the model: commit body, changed files and diff
+	Detail string
+}
+
+// GenerateChangelog renders a Keep a Changelog section for the commits in
+// from..to and prints it or, with appendFile, inserts it into the changelog
+// file. Unless offline, the models of the chain rewrite the commit titles into
+// user-facing entries.
+func GenerateChangelog(ollamaURL, ollamaModel string, fallbackModels []string, from, to, title string, offline bool, appendFile string) {
+	if from == "" {
+		// A tagged --to is the release being described, so start at the tag before it
+		if to == "HEAD" {
+			from = latestTag(to)
+		} else {
+			from = latestTag(to + "^")
+		}
+	}
+	commitRange := to
+	if from != "" {
+		commitRange = from + ".." + to
+	}
+
+	commits, err := readCommits(commitRange)
+	if err != nil {
+		fmt.Printf("%sError reading commits: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	entries := buildChangelogEntries(commits)
+	if len(entries) == 0 {
+		fmt.Printf("%sNo user-facing commits found in %s.%s\n", ColorYellow, commitRange, ColorReset)
+		return
+	}
+
+	if !offline {
+		chain := newModelChain(ollamaURL, ollamaModel, fallbackModels)
+		ctx := context.Background()
+
+		if chain.available(ctx) {
+			entries = rewriteChangelogEntries(ctx, chain, entries)
+		}
+	}
+
+	date := ""
+	if title == "" {
+		title = "Unreleased"
+		if to != "HEAD" {
+			title = to
+			date = commitDate(to)
+		}
+	}
+
+	section := renderChangelog(title, date, entries)
+	if appendFile == "" {
+		fmt.Print(section)
+		return
+	}
+
+	existing, err := os.ReadFile(appendFile)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Printf("%sError reading %s: %v%s\n", ColorRed, appendFile, err, ColorReset)
+		return
+	}
+	if err := os.WriteFile(appendFile, []byte(insertChangelogSection(string(existing), title, section)), 0o644); err != nil {
+		fmt.Printf("%sError writing %s: %v%s\n", ColorRed, appendFile, err, ColorReset)
+		return
+	}
+	fmt.Printf("%s✅ Changelog section '%s' added to %s%s\n", ColorGreen, title, appendFile, ColorReset)
+}
+
+// buildChangelogEntries classifies commits into changelog sections, skipping
+// non user-facing types and duplicate titles.
+func buildChangelogEntries(commits []commitInfo) []changelogEntry {
+	var entries []changelogEntry
+	for _, commit := range commits {
+		cc := parseConventionalCommit(commit.Subject, commit.Body)
+		section := changelogSection(cc)
+		if section == "" {
+			continue
+		}
+
+		text := capitalize(cc.Description)
+		if cc.Scope != "" {
+			text = fmt.Sprintf("**%s:** %s", cc.Scope, text)
+		}
+
+		entries = append(entries, changelogEntry{
+			Section:  section,
+			Text:     text,
+			Breaking: cc.Breaking,
+			Detail:   commit.Body + "\n" + commitDiff(commit.SHA),
+		})
+	}
+	return dedupeChangelogEntries(entries)
+}
+
+// changelogSection maps a commit to its Keep a Changelog section. Commits
+// that are not user-facing (docs, tests, chores) return an empty section
+// unless they are breaking.
+func changelogSection(cc conventionalCommit) string {
+	var section string
+	switch cc.Type {
+	case "feat":
+		section = "Added"
+	case "fix":
+		section = "Fixed"
+	case "perf", "refactor", "build", "deps", "style", "revert":
+		section = "Changed"
+	case "security":
+		section = "Security"
+	case "deprecate", "deprecated":
+		section = "Deprecated"
+	case "remove":
+		section = "Removed"
+	case "docs", "test", "tests", "chore", "ci", "wip":
+		section = ""
+	case "":
+		section = sectionFromVerb(cc.Description)
+	default:
+		section = "Changed"
+	}
+
+	if section == "" && cc.Breaking {
+		section = "Changed"
+	}
+	return section
+}
+
+// sectionFromVerb classifies non-conventional titles by their leading verb.
+func sectionFromVerb(description string) string {
+	fields := strings.Fields(strings.ToLower(description))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	switch fields[0] {
+	case "add", "adds", "added", "implement", "implements", "introduce", "introduces", "create", "creates", "support", "supports":
+		return "Added"
+	case "fix", "fixes", "fixed", "resolve", "resolves", "correct", "corrects":
+		return "Fixed"
+	case "remove", "removes", "removed", "delete", "deletes", "drop", "drops":
+		return "Removed"
+	case "deprecate", "deprecates":
+		return "Deprecated"
+	case "merge", "wip":
+		return ""
+	}
+	return "Changed"
+}
+
+func dedupeChangelogEntries(entries []changelogEntry) []changelogEntry {
+	seen := map[string]bool{}
+	var result []changelogEntry
+	for _, entry := range entries {
+		key := strings.TrimSuffix(strings.ToLower(strings.Join(strings.Fields(entry.Text), " ")), ".")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, entry)
+	}
+	return result
+}
+
+// rewriteChangelogEntries has the model chain rewrite the entries, in
+// batches of at most changelogBatchChars. Batches no model could rewrite keep
+// their commit titles.
+func rewriteChangelogEntries(ctx context.Context, chain *modelChain, entries []changelogEntry) []changelogEntry {
+	for _, batch := range changelogBatches(entries, changelogBatchChars) {
+		texts := make([]string, len(batch))
+		details := make([]string, len(batch))
+		for i, entry := range batch {
+			texts[i] = entry.Text
+			details[i] = entry.Detail
+		}
+
+		chain.run("changelog", func(client *ollama.Client) error {
+			rewritten, err := client.RewriteChangelogEntries(ctx, texts, details)
+			if err != nil {
+				return err
+			}
+			for i := range batch {
+				batch[i].Text = rewritten[i]
+			}
+			return nil
+		})
+	}
+	return dedupeChangelogEntries(entries)
+}
+
+// changelogBatches splits entries into consecutive batches whose texts and
+// details add up to at most limit characters. An entry larger than limit
+// gets a batch of its own.
+func changelogBatches(entries []changelogEntry, limit int) [][]changelogEntry {
+	var batches [][]changelogEntry
+	start, size := 0, 0
+	for i, entry := range entries {
+		entrySize := len(entry.Text) + len(entry.Detail)
+		if i > start && size+entrySize > limit {
+			batches = append(batches, entries[start:i])
+			start, size = i, 0
+		}
+		size += entrySize
+	}
+	if start < len(entries) {
+		batches = append(batches, entries[start:])
+	}
+	return batches
+}
+
+// renderChangelog renders entries as a Keep a Changelog version section.
+func renderChangelog(title, date string, entries []changelogEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## [%s]", title)
+	if date != "" {
+		fmt.Fprintf(&b, " - %s", date)
+	}
+	b.WriteString("\n")
+
+	for _, section := range changelogSections {
+		var lines []string
+		for _, entry := range entries {
+			if entry.Section != section {
+				continue
+			}
+			text := entry.Text
+			if entry.Breaking {
+				text = "**BREAKING:** " + text
+			}
+			lines = append(lines, "- "+text)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n", section, strings.Join(lines, "\n"))
+	}
+	return b.String()
+}
+
+// insertChangelogSection adds section to an existing changelog, creating the
+// standard header for a new file. A section with the same title is replaced,
+// so that regenerating "Unreleased" does not stack duplicates. Otherwise the
+// section goes above the newest version, below an Unreleased section.
+func insertChangelogSection(existing, title, section string) string {
+	if strings.TrimSpace(existing) == "" {
+		return "# Changelog\n\nAll notable changes to this project will be documented in this file.\n\n" + section
+	}
+
+	lines := strings.SplitAfter(existing, "\n")
+	insertAt := -1
+	for i := 0; i < len(lines); i++ {
+		heading, ok := changelogHeading(lines[i])
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(heading, title) {
+			end := changelogSectionEnd(lines, i+1)
+			rest := strings.Join(lines[end:], "")
+			if rest != "" {
+				rest = "\n" + rest
+			}
+			return strings.Join(lines[:i], "") + section + rest
+		}
+		if insertAt < 0 && !(strings.EqualFold(heading, "Unreleased") && !strings.EqualFold(title, "Unreleased")) {
+			insertAt = i
+		}
+	}
+
+	if insertAt >= 0 {
+		return strings.Join(lines[:insertAt], "") + section + "\n" + strings.Join(lines[insertAt:], "")
+	}
+	// Only an Unreleased section, or no versions yet
+	end := len(lines)
+	for i, line := range lines {
+		if _, ok := changelogHeading(line); ok {
+			end = changelogSectionEnd(lines, i+1)
+		}
+	}
+	head := strings.TrimRight(strings.Join(lines[:end], ""), "\n") + "\n\n" + section
+	if rest := strings.Join(lines[end:], ""); rest != "" {
+		return head + "\n" + rest
+	}
+	return head
+}
+
+// changelogHeading returns the title of a "## [title] - date" version heading.
+func changelogHeading(line string) (string, bool) {
+	if !strings.HasPrefix(line, "## ") {
+		return "", false
+	}
+	heading := strings.TrimSpace(strings.TrimPrefix(line, "## "))
+	if strings.HasPrefix(heading, "[") {
+		if end := strings.Index(heading, "]"); end >= 0 {
+			return heading[1:end], true
+		}
+	}
+	title, _, _ := strings.Cut(heading, " - ")
+	return strings.TrimSpace(title), true
+}
+
+// changelogSectionEnd returns the index of the line after the version
+// section starting before lines[start]: the next version heading, the link
+// references at the bottom of the file or the end.
+func changelogSectionEnd(lines []string, start int) int {
+	for i := start; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "## ") || changelogLinkPattern.MatchString(lines[i]) {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// commitDiff returns the files changed by a commit with their change stats,
+// followed by the start of its diff.
+func commitDiff(sha string) string {
+	output, err := exec.Command("git", "show", "--stat", "--patch", "--format=", sha).Output()
+	if err != nil {
+		return ""
+	}
+	diff := strings.TrimSpace(string(output))
+	if truncated := truncateText(diff, changelogDiffChars); truncated != diff {
+		return truncated + "\n[diff truncated]"
+	}
+	return diff
+}
+
+// commitDate returns the committer date of rev as YYYY-MM-DD.
+func commitDate(rev string) string {
+	output, err := exec.Command("git", "log", "-1", "--format=%cs", rev).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,174 @@
+package git
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChangelogSection(t *testing.T) {
+	tests := []struct {
+		subject  string
+		body     string
+		expected string
+	}{
+		{"feat: add export", "", "Added"},
+		{"fix(ui): align buttons", "", "Fixed"},
+		{"perf: cache lookups", "", "Changed"},
+		{"docs: fix typo", "", ""},
+		{"chore!: require go 1.24", "", "Changed"},
+		{"Add dark mode", "", "Added"},
+		{"Remove legacy importer", "", "Removed"},
+		{"Tweak spacing", "", "Changed"},
+		{"Merge branch 'main'", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			result := changelogSection(parseConventionalCommit(tt.subject, tt.body))
+			if result != tt.expected {
+				t.Errorf("changelogSection(%q) = %q, want %q", tt.subject, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderChangelog(t *testing.T) {
+	entries := dedupeChangelogEntries([]changelogEntry{
+		{Section: "Fixed", Text: "Align buttons"},
+		{Section: "Added", Text: "Add export"},
+		{Section: "Added", Text: "add  export."},
+		{Section: "Changed", Text: "Require Go 1.24", Breaking: true},
+	})
+
+	expected := `## [v1.3.0] - 2024-05-01
+
+### Added
+
+- Add export
+
+### Changed
+
+- **BREAKING:** Require Go 1.24
+
+### Fixed
+
+- Align buttons
+`
+
+	if result := renderChangelog("v1.3.0", "2024-05-01", entries); result != expected {
+		t.Errorf("renderChangelog() = %q, want %q", result, expected)
+	}
+}
+
+func TestInsertChangelogSection(t *testing.T) {
+	section := "## [v1.1.0]\n\n### Fixed\n\n- Fix crash\n"
+	unreleased := "## [Unreleased]\n\n### Added\n\n- Add export\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		title    string
+		section  string
+		expected string
+	}{
+		{
+			name:     "New changelog",
+			existing: "",
+			expected: "# Changelog\n\nAll notable changes to this project will be documented in this file.\n\n" + section,
+		},
+		{
+			name:     "Inserted above previous version",
+			existing: "# Changelog\n\nIntro.\n\n## [v1.0.0]\n\n- Initial release\n",
+			expected: "# Changelog\n\nIntro.\n\n" + section + "\n## [v1.0.0]\n\n- Initial release\n",
+		},
+		{
+			name:     "Appended when there are no versions yet",
+			existing: "# Changelog\n",
+			expected: "# Changelog\n\n" + section,
+		},
+		{
+			name:     "Release goes below Unreleased",
+			existing: "# Changelog\n\n## [Unreleased]\n\n- Pending\n\n## [v1.0.0]\n\n- Initial release\n",
+			expected: "# Changelog\n\n## [Unreleased]\n\n- Pending\n\n" + section + "\n## [v1.0.0]\n\n- Initial release\n",
+		},
+		{
+			name:     "Unreleased is replaced",
+			existing: "# Changelog\n\n## [Unreleased]\n\n- Old entry\n\n## [v1.0.0] - 2024-01-01\n\n- Initial release\n",
+			title:    "Unreleased",
+			section:  unreleased,
+			expected: "# Changelog\n\n" + unreleased + "\n## [v1.0.0] - 2024-01-01\n\n- Initial release\n",
+		},
+		{
+			name:     "Last section is replaced before link references",
+			existing: "# Changelog\n\n## [v1.1.0]\n\n- Old entry\n\n[v1.1.0]: https://example.com/v1.1.0\n",
+			expected: "# Changelog\n\n" + section + "\n[v1.1.0]: https://example.com/v1.1.0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, sec := tt.title, tt.section
+			if sec == "" {
+				title, sec = "v1.1.0", section
+			}
+			result := insertChangelogSection(tt.existing, title, sec)
+			if result != tt.expected {
+				t.Errorf("insertChangelogSection() = %q, want %q", result, tt.expected)
+			}
+			// Appending the same section again must not add a duplicate
+			if again := insertChangelogSection(result, title, sec); again != result {
+				t.Errorf("insertChangelogSection() twice = %q, want %q", again, result)
+			}
+		})
+	}
+}
+
+func TestCapitalize(t *testing.T) {
+	tests := map[string]string{
+		"add export": "Add export",
+		"ändern":     "Ändern",
+		"✨ sparkle":  "✨ sparkle",
+		"":           "",
+	}
+	for input, expected := range tests {
+		if result := capitalize(input); result != expected {
+			t.Errorf("capitalize(%q) = %q, want %q", input, result, expected)
+		}
+	}
+}
+
+func TestChangelogBatches(t *testing.T) {
+	entries := []changelogEntry{
+		{Text: "a", Detail: strings.Repeat("x", 40)},
+		{Text: "b", Detail: strings.Repeat("x", 40)},
+		{Text: "c", Detail: strings.Repeat("x", 200)},
+		{Text: "d"},
+	}
+
+	var sizes []int
+	for _, batch := range changelogBatches(entries, 100) {
+		sizes = append(sizes, len(batch))
+	}
+	if expected := []int{2, 1, 1}; !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("changelogBatches() sizes = %v, want %v", sizes, expected)
+	}
+	if batches := changelogBatches(nil, 100); len(batches) != 0 {
+		t.Errorf("changelogBatches(nil) = %v, want no batches", batches)
+	}
+}
+
+func TestRewriteChangelogEntriesUsesFallbackModel(t *testing.T) {
+	server := chainServer(t, []string{"llama3.2:3b"}, map[string]string{"llama3.2:3b": "1. Users can export reports."})
+	defer server.Close()
+
+	chain := newModelChain(server.URL, "qwen2.5-coder:7b", []string{"llama3.2:3b"})
+	chain.out = io.Discard
+	chain.available(t.Context())
+
+	entries := rewriteChangelogEntries(t.Context(), chain, []changelogEntry{{Section: "Added", Text: "Add export"}})
+	if len(entries) != 1 || entries[0].Text != "Users can export reports." {
+		t.Errorf("rewriteChangelogEntries() = %+v, want the fallback model's entry", entries)
+	}
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// conventionalHeaderPattern matches "type(scope)!: " at the start of a title.
+var conventionalHeaderPattern = regexp.MustCompile(`^([a-zA-Z]+)(\(([^)]*)\))?(!)?: `)
+
+// ticketPrefixPattern matches the ticket prefix added by extractTicketPrefix.
+var ticketPrefixPattern = regexp.MustCompile(`^[A-Z]+-\d+: `)
+
+// conventionalCommit is a commit message split into its Conventional
+// Commits parts. Type is empty for messages not in that format.
+type conventionalCommit struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+// parseConventionalCommit parses a commit subject and body. A leading ticket
+// prefix is ignored and a BREAKING CHANGE footer marks the commit breaking.
+func parseConventionalCommit(subject, body string) conventionalCommit {
+	subject = ticketPrefixPattern.ReplaceAllString(strings.TrimSpace(subject), "")
+
+	commit := conventionalCommit{Description: subject}
+	if matches := conventionalHeaderPattern.FindStringSubmatch(subject); matches != nil {
+		commit.Type = strings.ToLower(matches[1])
+		commit.Scope = matches[3]
+		commit.Breaking = matches[4] == "!"
+		commit.Description = strings.TrimSpace(subject[len(matches[0]):])
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			commit.Breaking = true
+		}
+	}
+
+	return commit
+}
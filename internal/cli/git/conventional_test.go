@@ -0,0 +1,50 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  string
+		body     string
+		expected conventionalCommit
+	}{
+		{
+			name:     "Type and description",
+			subject:  "feat: add login",
+			expected: conventionalCommit{Type: "feat", Description: "add login"},
+		},
+		{
+			name:     "Scope and breaking marker",
+			subject:  "fix(api)!: reject empty tokens",
+			expected: conventionalCommit{Type: "fix", Scope: "api", Breaking: true, Description: "reject empty tokens"},
+		},
+		{
+			name:     "Ticket prefix is ignored",
+			subject:  "BP-3648: refactor(db): pool connections",
+			expected: conventionalCommit{Type: "refactor", Scope: "db", Description: "pool connections"},
+		},
+		{
+			name:     "Breaking change footer",
+			subject:  "feat: drop v1 endpoints",
+			body:     "Removes the v1 API.\n\nBREAKING CHANGE: v1 clients must upgrade",
+			expected: conventionalCommit{Type: "feat", Breaking: true, Description: "drop v1 endpoints"},
+		},
+		{
+			name:     "Non-conventional subject",
+			subject:  "Update README.md",
+			expected: conventionalCommit{Description: "Update README.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseConventionalCommit(tt.subject, tt.body)
+			if result != tt.expected {
+				t.Errorf("parseConventionalCommit() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package git
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"regexp"
@@ -26,127 +28,356 @@ const (
 	ColorRed    = "\033[31m"
 )
 
-func GenerateCommitMessage(ollamaURL, ollamaModel string, showDiff bool, tone string, interactive bool, autoStage bool) {
-	if autoStage {
+// GenerateOptions controls how GenerateCommitMessage collects the changes
+// and talks to Ollama.
+type GenerateOptions struct {
+	OllamaURL   string
+	OllamaModel string
+	// FallbackModels are tried in order when OllamaModel is missing, times
+	// out or fails, before falling back to rule-based analysis
+	FallbackModels []string
+	ShowDiff       bool
+	Tone           string
+	Interactive    bool
+	// AutoStage stages all changes with 'git add -A' before generating
+	AutoStage bool
+	// SelectHunks lets the user pick the files and hunks to stage instead
+	SelectHunks bool
+	// Source selects where the changes are read from, the index by default
+	Source DiffSource
+	// Commit adds sign-off and co-author trailers to the message
+	Commit CommitOptions
+	// PairingPartners are offered as co-authors in interactive mode
+	PairingPartners []string
+	// LearnStyle adds the repository's commit conventions and example
+	// messages from git log to the prompt
+	LearnStyle bool
+	// SimilarCommits is how many past commits with the most similar diffs
+	// are added to the prompt as examples, zero disables retrieval
+	SimilarCommits int
+	// EmbedModel is the Ollama model used to embed diffs for retrieval
+	EmbedModel string
+	// Language is the language tag the message is written in, e.g. "de",
+	// empty for English
+	Language string
+	// JSON prints the message and the model that produced it as JSON
+	JSON bool
+	// NoCache generates a new message even when one was cached for the same
+	// diff and prompt, which are otherwise reused for CacheTTL
+	NoCache  bool
+	CacheTTL time.Duration
+}
+
+func GenerateCommitMessage(opts GenerateOptions) {
+	// With --json stdout only carries the JSON, so progress, warnings and
+	// prompts go to stderr
+	out := io.Writer(os.Stdout)
+	if opts.JSON {
+		out = os.Stderr
+	}
+
+	source, err := opts.Source.resolve()
+	if err != nil {
+		fmt.Fprintf(out, "%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	staged := source.Kind == DiffSourceStaged
+
+	commitOpts := opts.Commit
+	if opts.Interactive && staged && len(opts.PairingPartners) > 0 {
+		partners, err := selectPairingPartners(opts.PairingPartners, out)
+		if err != nil {
+			fmt.Fprintf(out, "%s%v%s\n", ColorRed, err, ColorReset)
+			return
+		}
+		commitOpts.CoAuthors = append(commitOpts.CoAuthors, partners...)
+	}
+	// Resolve the trailers before spending time on generation
+	trailers, err := commitOpts.trailers()
+	if err != nil {
+		fmt.Fprintf(out, "%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	var state repoState
+	if staged {
+		state = detectRepoState()
+		if state.Kind != "" {
+			logrus.WithField("state", state.Kind).Debug("operation in progress")
+		}
+		// Auto-staging marks unmerged files as resolved, so they must be free of conflict markers
+		if unresolved := unresolvedFiles(opts.AutoStage && !opts.SelectHunks); len(unresolved) > 0 {
+			fmt.Fprintf(out, "%sResolve the conflicts in %s before generating a commit message.%s\n", ColorRed, strings.Join(unresolved, ", "), ColorReset)
+			return
+		}
+	}
+
+	if !staged {
+		logrus.WithField("source", source.Kind).Debug("reading diff without staging")
+	} else if opts.SelectHunks {
+		selected, err := selectAndStageHunks(out)
+		if errors.Is(err, errSelectionAborted) {
+			fmt.Fprintln(out, "Commit not created.")
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(out, "%sError staging selected changes: %v%s\n", ColorRed, err, ColorReset)
+			return
+		}
+		if !selected {
+			fmt.Fprintf(out, "%sNo changes selected.%s\n", ColorYellow, ColorReset)
+			return
+		}
+	} else if opts.AutoStage {
 		logrus.Debug("Staging all changes...")
 		if err := stageAllChanges(); err != nil {
-			fmt.Printf("%sError staging changes: %v%s\n", ColorRed, err, ColorReset)
+			fmt.Fprintf(out, "%sError staging changes: %v%s\n", ColorRed, err, ColorReset)
 			return
 		}
 	}
 
-	diff, err := getStagedDiff()
+	diff, err := readDiff(source)
 	if err != nil {
-		fmt.Printf("%sError getting staged diff: %v%s\n", ColorRed, err, ColorReset)
+		fmt.Fprintf(out, "%sError getting diff: %v%s\n", ColorRed, err, ColorReset)
 		return
 	}
+	state.resolveConflicts()
 
 	if strings.TrimSpace(diff) == "" {
-		if autoStage {
-			fmt.Printf("%sNo changes found to stage and commit.%s\n", ColorYellow, ColorReset)
+		if !staged {
+			fmt.Fprintf(out, "%sNo changes found in the %s diff.%s\n", ColorYellow, source.Kind, ColorReset)
+		} else if opts.AutoStage {
+			fmt.Fprintf(out, "%sNo changes found to stage and commit.%s\n", ColorYellow, ColorReset)
 		} else {
-			fmt.Printf("%sNo staged changes found. Please stage your changes with 'git add' first.%s\n", ColorYellow, ColorReset)
+			fmt.Fprintf(out, "%sNo staged changes found. Please stage your changes with 'git add' first.%s\n", ColorYellow, ColorReset)
 		}
 		return
 	}
 
-	if showDiff {
-		fmt.Printf("%s%sGit diff output:%s\n", ColorBold, ColorBlue, ColorReset)
-		fmt.Printf("%s================%s\n", ColorBlue, ColorReset)
-		fmt.Println(diff)
-		fmt.Printf("%s================%s\n", ColorBlue, ColorReset)
-		fmt.Println()
+	if opts.ShowDiff {
+		fmt.Fprintf(out, "%s%sGit diff output:%s\n", ColorBold, ColorBlue, ColorReset)
+		fmt.Fprintf(out, "%s================%s\n", ColorBlue, ColorReset)
+		fmt.Fprintln(out, diff)
+		fmt.Fprintf(out, "%s================%s\n", ColorBlue, ColorReset)
+		fmt.Fprintln(out)
 	}
 
 	logrus.
 		WithField("llm", "ollama").
-		WithField("url", ollamaURL).
-		WithField("model", ollamaModel).
+		WithField("url", opts.OllamaURL).
+		WithField("model", opts.OllamaModel).
 		Debug("generating commit message")
 
-	client := ollama.NewClient(ollamaURL, ollamaModel)
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-
-	// Get current branch and extract ticket prefix
-	branchName, err := getCurrentBranch()
-	var ticketPrefix string
-	if err != nil {
-		fmt.Printf("%sWarning: Could not determine current branch, commit message will not include ticket prefix%s\n", ColorYellow, ColorReset)
-		ticketPrefix = ""
-	} else {
-		ticketPrefix = extractTicketPrefix(branchName)
-		if ticketPrefix == "" && branchName != "main" && branchName != "master" {
-			fmt.Printf("%sWarning: Branch '%s' does not match ticket pattern, commit message will not include ticket prefix%s\n", ColorYellow, branchName, ColorReset)
-		}
+	chain := newModelChain(opts.OllamaURL, opts.OllamaModel, opts.FallbackModels)
+	chain.out = out
+	if !opts.NoCache {
+		chain.cache = newMessageCache(opts.CacheTTL)
 	}
+	ctx := context.Background()
+
+	ticketPrefix := currentTicketPrefix(out)
+	available := chain.available(ctx)
 
-	// Check if Ollama is available
-	var commitMsg ollama.CommitMessage
-	if err := client.HealthCheck(ctx); err != nil {
-		fmt.Printf("Ollama health check failed: %v\n", err)
-		fmt.Println("Falling back to basic analysis...")
-		title := analyzeAndGenerateMessage(diff)
-		commitMsg = ollama.CommitMessage{
-			Title:       ticketPrefix + title,
-			Description: "Code changes as analyzed from the git diff.",
+	pc := diffPromptContext(diff, ticketPrefix)
+	pc.State = state.promptContext()
+	pc.Language = opts.Language
+	if available && opts.LearnStyle {
+		style, err := learnedCommitStyle()
+		if err != nil {
+			fmt.Fprintf(out, "%sWarning: could not learn the commit style: %v%s\n", ColorYellow, err, ColorReset)
+		} else {
+			pc.Style = style.instructions()
+			pc.Examples = style.Examples
 		}
-	} else {
-		var err error
-		commitMsg, err = client.GenerateCommitMessage(ctx, diff, tone)
+	}
+	if available && opts.SimilarCommits > 0 {
+		similar, err := similarCommitMessages(opts.OllamaURL, opts.EmbedModel, diff, opts.SimilarCommits, out)
 		if err != nil {
-			fmt.Printf("Error generating commit message with ollama: %v\n", err)
-			fmt.Printf("Falling back to basic analysis...")
-			title := analyzeAndGenerateMessage(diff)
-			commitMsg = ollama.CommitMessage{
-				Title:       ticketPrefix + title,
-				Description: "Code changes as analyzed from the git diff.",
-			}
+			fmt.Fprintf(out, "%sWarning: could not retrieve similar commits: %v%s\n", ColorYellow, err, ColorReset)
 		} else {
-			// Add ticket prefix to LLM-generated title
-			commitMsg.Title = ticketPrefix + commitMsg.Title
+			// Similar changes are the better examples, so they come first
+			pc.Examples = appendMissing(similar, pc.Examples)
 		}
 	}
 
-	fmt.Printf("%sGenerated commit message:%s\n", ColorBold+ColorBlue, ColorReset)
-	fmt.Printf("%sTitle:%s %s%s%s\n", ColorBold+ColorCyan, ColorReset, ColorGreen, commitMsg.Title, ColorReset)
-	fmt.Printf("%sDescription:%s %s%s%s\n", ColorBold+ColorCyan, ColorReset, ColorYellow, commitMsg.Description, ColorReset)
+	message := func() (ollama.CommitMessage, chainLink) {
+		commitMsg, link := generateMessage(chain, diff, opts.Tone, ticketPrefix, pc)
+		if state.Kind != "" {
+			commitMsg = applyRepoState(commitMsg, state, ticketPrefix, !link.offline())
+		}
+		commitMsg.Trailers = mergeTrailers(commitMsg.Trailers, trailers)
+		return commitMsg, link
+	}
+	commitMsg, link := message()
 
-	if interactive {
-		fmt.Print("\nDo you want to create a commit with this message? (y/N): ")
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Printf("Error reading input: %v\n", err)
+	if opts.JSON {
+		if err := writeMessageJSON(os.Stdout, commitMsg, commitOpts.Wrap, chain, link); err != nil {
+			fmt.Fprintf(out, "%sError writing JSON: %v%s\n", ColorRed, err, ColorReset)
 			return
 		}
+	} else {
+		printCommitMessage(commitMsg, out)
+	}
 
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response == "y" || response == "yes" {
-			if err := createCommit(commitMsg.Title, commitMsg.Description); err != nil {
-				fmt.Printf("%sError creating commit: %v%s\n", ColorRed, err, ColorReset)
+	if opts.Interactive && !staged {
+		fmt.Fprintf(out, "\n%sCommits can only be created from staged changes, skipping commit.%s\n", ColorYellow, ColorReset)
+		return
+	}
+
+	if opts.Interactive {
+		// The JSON already went to stdout, so it cannot be replaced
+		question := "\nDo you want to create a commit with this message? (y/N, r to regenerate): "
+		if opts.JSON {
+			question = "\nDo you want to create a commit with this message? (y/N): "
+		}
+
+		var answer string
+		for {
+			if commitMsg.Breaking != "" {
+				fmt.Fprintf(out, "\n%s⚠️  This commit contains breaking changes and will be marked as such.%s\n", ColorBold+ColorRed, ColorReset)
+			}
+			answer, err = ask(question, out)
+			if err != nil {
+				fmt.Fprintf(out, "Error reading input: %v\n", err)
 				return
 			}
-			fmt.Printf("%s✅ Commit created successfully!%s\n", ColorGreen, ColorReset)
+			if opts.JSON || (answer != "r" && answer != "regenerate") {
+				break
+			}
+
+			// The new message replaces the cached one
+			chain.regenerate = true
+			commitMsg, _ = message()
+			fmt.Fprintln(out)
+			printCommitMessage(commitMsg, out)
+		}
+
+		if answer == "y" || answer == "yes" {
+			if err := createCommit(commitMsg, commitOpts); err != nil {
+				fmt.Fprintf(out, "%sError creating commit: %v%s\n", ColorRed, err, ColorReset)
+				return
+			}
+			fmt.Fprintf(out, "%s✅ Commit created successfully!%s\n", ColorGreen, ColorReset)
 
 			if err := pushCommit(); err != nil {
-				fmt.Printf("%sError pushing commit: %v%s\n", ColorRed, err, ColorReset)
+				fmt.Fprintf(out, "%sError pushing commit: %v%s\n", ColorRed, err, ColorReset)
 				return
 			}
-			fmt.Printf("%s🚀Commit pushed successfully!%s\n", ColorCyan, ColorReset)
+			fmt.Fprintf(out, "%s🚀Commit pushed successfully!%s\n", ColorCyan, ColorReset)
 		} else {
-			fmt.Println("Commit not created.")
+			fmt.Fprintln(out, "Commit not created.")
 		}
 	}
 }
 
-func getStagedDiff() (string, error) {
-	cmd := exec.Command("git", "diff", "--staged")
-	output, err := cmd.Output()
+// currentTicketPrefix returns the ticket prefix for the checked out branch,
+// warning the user when none can be derived.
+func currentTicketPrefix(w io.Writer) string {
+	branchName, err := getCurrentBranch()
 	if err != nil {
-		return "", fmt.Errorf("failed to get git diff: %w", err)
+		fmt.Fprintf(w, "%sWarning: Could not determine current branch, commit message will not include ticket prefix%s\n", ColorYellow, ColorReset)
+		return ""
+	}
+
+	ticketPrefix := extractTicketPrefix(branchName)
+	if ticketPrefix == "" && branchName != "main" && branchName != "master" {
+		fmt.Fprintf(w, "%sWarning: Branch '%s' does not match ticket pattern, commit message will not include ticket prefix%s\n", ColorYellow, branchName, ColorReset)
+	}
+	return ticketPrefix
+}
+
+// diffPromptContext describes diff and the current branch for the prompt.
+func diffPromptContext(diff, ticketPrefix string) ollama.PromptContext {
+	files := parseDiff(diff)
+	pc := ollama.PromptContext{
+		Ticket: strings.TrimSuffix(ticketPrefix, ": "),
+		Stats:  diffStats(files),
+	}
+	if branch, err := getCurrentBranch(); err == nil {
+		pc.Branch = branch
+	}
+	for _, f := range files {
+		pc.Files = append(pc.Files, f.Path)
+	}
+	return pc
+}
+
+// generateMessage produces the commit message for diff with the model
+// chain, which ends in rule-based analysis, and applies the breaking change
+// marker and ticket prefix. It also returns the link that produced it.
+func generateMessage(chain *modelChain, diff, tone, ticketPrefix string, pc ollama.PromptContext) (ollama.CommitMessage, chainLink) {
+	commitMsg, link := chain.generate(diff, tone, pc)
+	logrus.WithField("link", link.Index).WithField("model", link.Model).Debug("generated commit message")
+
+	if changes := detectBreakingChanges(diff); len(changes) > 0 {
+		logrus.WithField("changes", changes).Debug("detected breaking changes")
+		commitMsg.Title = markBreakingTitle(commitMsg.Title)
+		commitMsg.Breaking = strings.Join(changes, "; ")
+	}
+
+	// Add ticket prefix to the title
+	commitMsg.Title = ticketPrefix + commitMsg.Title
+	return commitMsg, link
+}
+
+// applyRepoState makes a message fit the in-progress merge, revert or
+// cherry-pick, using the state's own title when the model was unavailable.
+func applyRepoState(commitMsg ollama.CommitMessage, state repoState, ticketPrefix string, available bool) ollama.CommitMessage {
+	title := strings.TrimPrefix(commitMsg.Title, ticketPrefix)
+	if !available {
+		title = state.fallbackTitle()
+	}
+
+	var trailers []string
+	title, commitMsg.Description, trailers = state.apply(title, commitMsg.Description)
+	commitMsg.Title = ticketPrefix + title
+	commitMsg.Trailers = append(commitMsg.Trailers, trailers...)
+	return commitMsg
+}
+
+func fallbackCommitMessage(diff, language string) ollama.CommitMessage {
+	return ollama.CommitMessage{
+		Title:       analyzeAndGenerateMessage(diff, language),
+		Description: phrasesFor(language).Description,
+	}
+}
+
+func printCommitMessage(commitMsg ollama.CommitMessage, w io.Writer) {
+	fmt.Fprintf(w, "%sGenerated commit message:%s\n", ColorBold+ColorBlue, ColorReset)
+	fmt.Fprintf(w, "%sTitle:%s %s%s%s\n", ColorBold+ColorCyan, ColorReset, ColorGreen, commitMsg.Title, ColorReset)
+	fmt.Fprintf(w, "%sDescription:%s %s%s%s\n", ColorBold+ColorCyan, ColorReset, ColorYellow, commitMsg.Description, ColorReset)
+	if commitMsg.Breaking != "" {
+		fmt.Fprintf(w, "%sBREAKING CHANGE:%s %s%s%s\n", ColorBold+ColorRed, ColorReset, ColorRed, commitMsg.Breaking, ColorReset)
+	}
+	for _, trailer := range commitMsg.Trailers {
+		fmt.Fprintf(w, "%s%s%s\n", ColorCyan, trailer, ColorReset)
 	}
-	return string(output), nil
+}
+
+// stdin is shared so that consecutive prompts do not lose buffered input.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question and reports whether the user answered yes.
+func confirm(question string, w io.Writer) (bool, error) {
+	response, err := ask(question, w)
+	if err != nil {
+		return false, err
+	}
+	return response == "y" || response == "yes", nil
+}
+
+// ask prints question and returns the answer, trimmed and in lower case.
+func ask(question string, w io.Writer) (string, error) {
+	fmt.Fprint(w, question)
+	response, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(strings.TrimSpace(response)), nil
+}
+
+func getStagedDiff() (string, error) {
+	return runGitDiff("diff", "--staged")
 }
 
 func stageAllChanges() error {
@@ -158,15 +389,62 @@ func stageAllChanges() error {
 	return nil
 }
 
-func createCommit(title, description string) error {
-	cmd := exec.Command("git", "commit", "-m", title, "-m", description)
+// createCommit commits the staged changes with msg, signing it as requested
+// by opts. extraArgs are passed to git commit before the message, e.g.
+// "--amend". The message is written to a file and committed with
+// --cleanup=strip, merging in commit.template and wrapping the body at
+// opts.Wrap. Signing failures are returned as a *SigningError.
+func createCommit(msg ollama.CommitMessage, opts CommitOptions, extraArgs ...string) error {
+	message, err := renderCommitMessage(msg, opts)
+	if err != nil {
+		return err
+	}
+
+	path, err := writeMessageFile(message)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	// Lines starting with the comment character would be stripped
+	args := []string{"-c", "core.commentChar=" + safeCommentChar(message, commentChar()), "commit"}
+	args = append(args, extraArgs...)
+	args = append(args, opts.signArgs()...)
+	args = append(args, "--cleanup=strip", "-F", path)
+
+	cmd := exec.Command("git", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if signErr, ok := signingFailure(string(output)); ok {
+			logrus.WithField("output", string(output)).Debug("commit signing failed")
+			return signErr
+		}
 		return fmt.Errorf("git commit failed: %w\nOutput: %s", err, string(output))
 	}
 	return nil
 }
 
+// formatCommitMessage renders msg the way createCommit records it: title,
+// description and footer separated by blank lines.
+func formatCommitMessage(msg ollama.CommitMessage) string {
+	paragraphs := []string{msg.Title, msg.Description}
+	if footer := footerLines(msg); len(footer) > 0 {
+		paragraphs = append(paragraphs, strings.Join(footer, "\n"))
+	}
+	return strings.Join(paragraphs, "\n\n") + "\n"
+}
+
+// footerLines returns the breaking change footer followed by the trailers.
+// They share the last paragraph so git and Conventional Commits tooling
+// both recognise them.
+func footerLines(msg ollama.CommitMessage) []string {
+	var footer []string
+	if msg.Breaking != "" {
+		footer = append(footer, "BREAKING CHANGE: "+msg.Breaking)
+	}
+	return append(footer, msg.Trailers...)
+}
+
 func pushCommit() error {
 	cmd := exec.Command("git", "push")
 	output, err := cmd.CombinedOutput()
@@ -219,7 +497,10 @@ func extractTicketPrefix(branchName string) string {
 	return ""
 }
 
-func analyzeAndGenerateMessage(diff string) string {
+// analyzeAndGenerateMessage derives a commit title from the files a diff
+// touches, using the verbs of language.
+func analyzeAndGenerateMessage(diff, language string) string {
+	phrases := phrasesFor(language)
 	lines := strings.Split(diff, "\n")
 
 	var addedFiles []string
@@ -229,9 +510,7 @@ func analyzeAndGenerateMessage(diff string) string {
 
 	for _, line := range lines {
 		if strings.HasPrefix(line, "diff --git") {
-			parts := strings.Fields(line)
-			if len(parts) >= 4 {
-				fileName := strings.TrimPrefix(parts[3], "b/")
+			if fileName := diffPath(line); fileName != "" {
 				modifiedFiles = append(modifiedFiles, fileName)
 			}
 		} else if strings.HasPrefix(line, "new file mode") {
@@ -253,29 +532,29 @@ func analyzeAndGenerateMessage(diff string) string {
 
 	if len(addedFiles) > 0 {
 		if len(addedFiles) == 1 {
-			return fmt.Sprintf("Add %s", addedFiles[0])
+			return fmt.Sprintf(phrases.Add, addedFiles[0])
 		}
-		return fmt.Sprintf("Add %d new files", len(addedFiles))
+		return fmt.Sprintf(phrases.AddMany, len(addedFiles))
 	}
 
 	if len(deletedFiles) > 0 {
 		if len(deletedFiles) == 1 {
-			return fmt.Sprintf("Remove %s", deletedFiles[0])
+			return fmt.Sprintf(phrases.Remove, deletedFiles[0])
 		}
-		return fmt.Sprintf("Remove %d files", len(deletedFiles))
+		return fmt.Sprintf(phrases.RemoveMany, len(deletedFiles))
 	}
 
 	if len(modifiedFiles) > 0 {
 		if len(modifiedFiles) == 1 {
 			if addedLines > deletedLines*2 {
-				return fmt.Sprintf("Enhance %s", modifiedFiles[0])
+				return fmt.Sprintf(phrases.Enhance, modifiedFiles[0])
 			} else if deletedLines > addedLines*2 {
-				return fmt.Sprintf("Refactor %s", modifiedFiles[0])
+				return fmt.Sprintf(phrases.Refactor, modifiedFiles[0])
 			}
-			return fmt.Sprintf("Update %s", modifiedFiles[0])
+			return fmt.Sprintf(phrases.Update, modifiedFiles[0])
 		}
-		return fmt.Sprintf("Update %d files", len(modifiedFiles))
+		return fmt.Sprintf(phrases.UpdateMany, len(modifiedFiles))
 	}
 
-	return "Update project files"
+	return phrases.UpdateProject
 }
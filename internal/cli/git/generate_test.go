@@ -1,7 +1,16 @@
 package git
 
 import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
 )
 
 func TestExtractTicketPrefix(t *testing.T) {
@@ -247,10 +256,110 @@ index 2345678..8901bcd 100644
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := analyzeAndGenerateMessage(tt.diff)
+			result := analyzeAndGenerateMessage(tt.diff, "")
 			if result != tt.expected {
 				t.Errorf("analyzeAndGenerateMessage() = %q, want %q", result, tt.expected)
 			}
 		})
 	}
 }
+
+func TestFormatCommitMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      ollama.CommitMessage
+		expected string
+	}{
+		{
+			name:     "Title and description",
+			msg:      ollama.CommitMessage{Title: "Add login", Description: "Adds a login form."},
+			expected: "Add login\n\nAdds a login form.\n",
+		},
+		{
+			name:     "Breaking change footer",
+			msg:      ollama.CommitMessage{Title: "feat!: drop v1", Description: "Removes the v1 API.", Breaking: "removed exported func V1"},
+			expected: "feat!: drop v1\n\nRemoves the v1 API.\n\nBREAKING CHANGE: removed exported func V1\n",
+		},
+		{
+			name: "Footer and trailers share the last paragraph",
+			msg: ollama.CommitMessage{
+				Title:       "feat!: drop v1",
+				Description: "Removes the v1 API.",
+				Breaking:    "removed exported func V1",
+				Trailers:    []string{"Co-authored-by: Ada <ada@example.com>"},
+			},
+			expected: "feat!: drop v1\n\nRemoves the v1 API.\n\nBREAKING CHANGE: removed exported func V1\nCo-authored-by: Ada <ada@example.com>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := formatCommitMessage(tt.msg)
+			if result != tt.expected {
+				t.Errorf("formatCommitMessage() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateCommitMessageJSONKeepsStdoutClean(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "a.txt", "a\n")
+	git("add", ".")
+
+	ollama.SetTimeouts(ollama.DefaultTimeouts(), ollama.RetryPolicy{})
+	defer ollama.SetTimeouts(ollama.DefaultTimeouts(), ollama.DefaultRetryPolicy())
+
+	stdout, stderr := captureOutput(t, func() {
+		GenerateCommitMessage(GenerateOptions{OllamaURL: "http://127.0.0.1:1", OllamaModel: "llama3.2", JSON: true, NoCache: true})
+	})
+
+	var result messageJSON
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("stdout should only hold the JSON, got:\n%s", stdout)
+	}
+	if result.Source.Model != offlineModel {
+		t.Errorf("message source = %q, want %q", result.Source.Model, offlineModel)
+	}
+	if !strings.Contains(stderr, "health check failed") {
+		t.Errorf("diagnostics should go to stderr, got:\n%s", stderr)
+	}
+}
+
+func TestGenerateCommitMessageRegenerate(t *testing.T) {
+	git := newTestRepo(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	writeTestFile(t, "a.txt", "a\n")
+	git("add", ".")
+
+	titles := []string{"TITLE: feat: first", "TITLE: feat: second", "TITLE: feat: third"}
+	generated := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			json.NewEncoder(w).Encode(ollama.TagsResponse{Models: []ollama.ModelInfo{{Name: "llama3.2:latest"}}})
+		case "/api/generate":
+			json.NewEncoder(w).Encode(ollama.GenerateResponse{Response: titles[generated], Done: true})
+			generated++
+		}
+	}))
+	defer server.Close()
+
+	opts := GenerateOptions{OllamaURL: server.URL, OllamaModel: "llama3.2", Interactive: true, CacheTTL: time.Hour}
+	stdin = bufio.NewReader(strings.NewReader("r\nn\n"))
+	defer func() { stdin = bufio.NewReader(os.Stdin) }()
+	stdout, _ := captureOutput(t, func() { GenerateCommitMessage(opts) })
+
+	if generated != 2 || !strings.Contains(stdout, "feat: second") {
+		t.Fatalf("answering r should generate a new message, made %d requests:\n%s", generated, stdout)
+	}
+
+	// The regenerated message replaced the cached one
+	opts.Interactive = false
+	stdout, _ = captureOutput(t, func() { GenerateCommitMessage(opts) })
+	if generated != 2 || !strings.Contains(stdout, "feat: second") {
+		t.Errorf("the regenerated message should be cached, made %d requests:\n%s", generated, stdout)
+	}
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// commitInfo is a commit read from git log.
+type commitInfo struct {
+	SHA         string
+	Subject     string
+	Body        string
+	AuthorName  string
+	AuthorEmail string
+}
+
+// readCommits returns the non-merge commits in commitRange, oldest first.
+func readCommits(commitRange string) ([]commitInfo, error) {
+	return logCommits("--reverse", commitRange)
+}
+
+// readRecentCommits returns up to limit non-merge commits reachable from
+// HEAD, newest first.
+func readRecentCommits(limit int) ([]commitInfo, error) {
+	return logCommits("-n", strconv.Itoa(limit), "HEAD")
+}
+
+func logCommits(args ...string) ([]commitInfo, error) {
+	// Unit and record separators keep multi-line bodies intact
+	args = append([]string{"log", "--no-merges", "--format=%H%x1f%s%x1f%b%x1f%an%x1f%ae%x1e"}, args...)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log %s failed: %w", args[len(args)-1], err)
+	}
+
+	var commits []commitInfo
+	for _, record := range strings.Split(string(output), "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		commits = append(commits, commitInfo{
+			SHA:         fields[0],
+			Subject:     fields[1],
+			Body:        strings.TrimSpace(fields[2]),
+			AuthorName:  fields[3],
+			AuthorEmail: fields[4],
+		})
+	}
+	return commits, nil
+}
+
+// latestTag returns the most recent tag reachable from rev, or an empty
+// string when there is none.
+func latestTag(rev string) string {
+	output, err := exec.Command("git", "describe", "--tags", "--abbrev=0", rev).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// messageJSON is the --json output of a generated commit message.
+type messageJSON struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Breaking    string   `json:"breaking,omitempty"`
+	Trailers    []string `json:"trailers,omitempty"`
+	// Message is the full commit message as it would be committed
+	Message string     `json:"message"`
+	Source  sourceJSON `json:"source"`
+}
+
+// sourceJSON records which link of the model chain produced the message.
+type sourceJSON struct {
+	// Link is the 1-based position of Model in Chain
+	Link  int      `json:"link"`
+	Model string   `json:"model"`
+	Chain []string `json:"chain"`
+	// Cached is set when the message was reused from an earlier run
+	Cached bool `json:"cached"`
+}
+
+func writeMessageJSON(w io.Writer, msg ollama.CommitMessage, wrap int, chain *modelChain, link chainLink) error {
+	out := messageJSON{
+		Title:       msg.Title,
+		Description: msg.Description,
+		Breaking:    msg.Breaking,
+		Trailers:    msg.Trailers,
+		Message:     formatCommitMessage(wrapMessage(msg, wrap)),
+		Source: sourceJSON{
+			Link:   link.Index,
+			Model:  link.Model,
+			Chain:  chain.links(),
+			Cached: link.Cached,
+		},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
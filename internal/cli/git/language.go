@@ -0,0 +1,158 @@
+package git
+
+import "strings"
+
+// fallbackPhrases are the format strings the rule-based fallback builds
+// commit messages from.
+type fallbackPhrases struct {
+	Add           string
+	AddMany       string
+	Remove        string
+	RemoveMany    string
+	Enhance       string
+	Refactor      string
+	Update        string
+	UpdateMany    string
+	UpdateProject string
+	Description   string
+}
+
+var englishPhrases = fallbackPhrases{
+	Add:           "Add %s",
+	AddMany:       "Add %d new files",
+	Remove:        "Remove %s",
+	RemoveMany:    "Remove %d files",
+	Enhance:       "Enhance %s",
+	Refactor:      "Refactor %s",
+	Update:        "Update %s",
+	UpdateMany:    "Update %d files",
+	UpdateProject: "Update project files",
+	Description:   "Code changes as analyzed from the git diff.",
+}
+
+// localizedPhrases are keyed by lowercase language tag. A region specific
+// entry wins over the base language.
+var localizedPhrases = map[string]fallbackPhrases{
+	"de": {
+		Add:           "%s hinzufügen",
+		AddMany:       "%d neue Dateien hinzufügen",
+		Remove:        "%s entfernen",
+		RemoveMany:    "%d Dateien entfernen",
+		Enhance:       "%s erweitern",
+		Refactor:      "%s überarbeiten",
+		Update:        "%s aktualisieren",
+		UpdateMany:    "%d Dateien aktualisieren",
+		UpdateProject: "Projektdateien aktualisieren",
+		Description:   "Codeänderungen, aus dem Git-Diff analysiert.",
+	},
+	"es": {
+		Add:           "Añadir %s",
+		AddMany:       "Añadir %d archivos nuevos",
+		Remove:        "Eliminar %s",
+		RemoveMany:    "Eliminar %d archivos",
+		Enhance:       "Mejorar %s",
+		Refactor:      "Refactorizar %s",
+		Update:        "Actualizar %s",
+		UpdateMany:    "Actualizar %d archivos",
+		UpdateProject: "Actualizar archivos del proyecto",
+		Description:   "Cambios de código analizados a partir del diff de git.",
+	},
+	"fr": {
+		Add:           "Ajouter %s",
+		AddMany:       "Ajouter %d nouveaux fichiers",
+		Remove:        "Supprimer %s",
+		RemoveMany:    "Supprimer %d fichiers",
+		Enhance:       "Améliorer %s",
+		Refactor:      "Refactoriser %s",
+		Update:        "Mettre à jour %s",
+		UpdateMany:    "Mettre à jour %d fichiers",
+		UpdateProject: "Mettre à jour les fichiers du projet",
+		Description:   "Modifications du code analysées à partir du diff git.",
+	},
+	"it": {
+		Add:           "Aggiungi %s",
+		AddMany:       "Aggiungi %d nuovi file",
+		Remove:        "Rimuovi %s",
+		RemoveMany:    "Rimuovi %d file",
+		Enhance:       "Migliora %s",
+		Refactor:      "Rifattorizza %s",
+		Update:        "Aggiorna %s",
+		UpdateMany:    "Aggiorna %d file",
+		UpdateProject: "Aggiorna i file del progetto",
+		Description:   "Modifiche al codice analizzate dal diff git.",
+	},
+	"nl": {
+		Add:           "%s toevoegen",
+		AddMany:       "%d nieuwe bestanden toevoegen",
+		Remove:        "%s verwijderen",
+		RemoveMany:    "%d bestanden verwijderen",
+		Enhance:       "%s uitbreiden",
+		Refactor:      "%s herstructureren",
+		Update:        "%s bijwerken",
+		UpdateMany:    "%d bestanden bijwerken",
+		UpdateProject: "Projectbestanden bijwerken",
+		Description:   "Codewijzigingen geanalyseerd uit de git-diff.",
+	},
+	"pt": {
+		Add:           "Adicionar %s",
+		AddMany:       "Adicionar %d novos arquivos",
+		Remove:        "Remover %s",
+		RemoveMany:    "Remover %d arquivos",
+		Enhance:       "Melhorar %s",
+		Refactor:      "Refatorar %s",
+		Update:        "Atualizar %s",
+		UpdateMany:    "Atualizar %d arquivos",
+		UpdateProject: "Atualizar arquivos do projeto",
+		Description:   "Alterações de código analisadas a partir do diff do git.",
+	},
+	"pt-pt": {
+		Add:           "Adicionar %s",
+		AddMany:       "Adicionar %d novos ficheiros",
+		Remove:        "Remover %s",
+		RemoveMany:    "Remover %d ficheiros",
+		Enhance:       "Melhorar %s",
+		Refactor:      "Refatorar %s",
+		Update:        "Atualizar %s",
+		UpdateMany:    "Atualizar %d ficheiros",
+		UpdateProject: "Atualizar ficheiros do projeto",
+		Description:   "Alterações de código analisadas a partir do diff do git.",
+	},
+	"ja": {
+		Add:           "%s を追加",
+		AddMany:       "%d 個の新規ファイルを追加",
+		Remove:        "%s を削除",
+		RemoveMany:    "%d 個のファイルを削除",
+		Enhance:       "%s を拡張",
+		Refactor:      "%s をリファクタリング",
+		Update:        "%s を更新",
+		UpdateMany:    "%d 個のファイルを更新",
+		UpdateProject: "プロジェクトファイルを更新",
+		Description:   "git diff から解析したコードの変更。",
+	},
+	"zh": {
+		Add:           "添加 %s",
+		AddMany:       "添加 %d 个新文件",
+		Remove:        "删除 %s",
+		RemoveMany:    "删除 %d 个文件",
+		Enhance:       "增强 %s",
+		Refactor:      "重构 %s",
+		Update:        "更新 %s",
+		UpdateMany:    "更新 %d 个文件",
+		UpdateProject: "更新项目文件",
+		Description:   "根据 git diff 分析的代码变更。",
+	},
+}
+
+// phrasesFor returns the fallback phrases for a language tag, English for
+// an empty or unknown tag.
+func phrasesFor(language string) fallbackPhrases {
+	tag := strings.ToLower(language)
+	if phrases, ok := localizedPhrases[tag]; ok {
+		return phrases
+	}
+	base, _, _ := strings.Cut(tag, "-")
+	if phrases, ok := localizedPhrases[base]; ok {
+		return phrases
+	}
+	return englishPhrases
+}
@@ -0,0 +1,42 @@
+package git
+
+import "testing"
+
+func TestAnalyzeAndGenerateMessageLocalized(t *testing.T) {
+	added := `diff --git a/new-file.go b/new-file.go
+new file mode 100644
+index 0000000..1234567
+--- /dev/null
++++ b/new-file.go
+@@ -0,0 +1 @@
++package main`
+
+	tests := []struct {
+		language string
+		diff     string
+		expected string
+	}{
+		{language: "de", diff: added, expected: "new-file.go hinzufügen"},
+		{language: "ja", diff: added, expected: "new-file.go を追加"},
+		{language: "pt-BR", diff: "", expected: "Atualizar arquivos do projeto"},
+		{language: "pt-PT", diff: "", expected: "Atualizar ficheiros do projeto"},
+		{language: "fr-CA", diff: added, expected: "Ajouter new-file.go"},
+		{language: "xx", diff: added, expected: "Add new-file.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			result := analyzeAndGenerateMessage(tt.diff, tt.language)
+			if result != tt.expected {
+				t.Errorf("analyzeAndGenerateMessage(%q) = %q, want %q", tt.language, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFallbackCommitMessageLocalized(t *testing.T) {
+	msg := fallbackCommitMessage("", "de")
+	if msg.Description != "Codeänderungen, aus dem Git-Diff analysiert." {
+		t.Errorf("fallbackCommitMessage() description = %q, want the German description", msg.Description)
+	}
+}
@@ -0,0 +1,158 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// commentCharCandidates are tried in order when the configured comment
+// character would strip lines of the message, the same set git uses for
+// core.commentChar=auto.
+const commentCharCandidates = "#;@!$%^&|:"
+
+var (
+	// "Token: value" lines in a commit template become trailers
+	templateTrailerPattern = regexp.MustCompile(`^[A-Za-z][\w-]*: \S`)
+	// "Token:" lines without a value are placeholders left to fill in
+	templatePlaceholderPattern = regexp.MustCompile(`^[A-Za-z][\w-]*:\s*$`)
+	// Template text that only tells the author what to write, e.g.
+	// "<describe the change>", "Why is this needed?", "Details:" or "----"
+	templateBoilerplatePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^[<\[{(][^@]*[>\]})]$`),
+		regexp.MustCompile(`\?$|:$`),
+		regexp.MustCompile(`^[-=_*~+.]{3,}$`),
+		regexp.MustCompile(`\.\.\.|___|\b(?:TODO|TBD|XXX)\b`),
+		regexp.MustCompile(`(?i)^(?:please\b|describe|explain|summari[sz]e|enter|insert|provide|write|fill in|replace this)\b`),
+	}
+)
+
+// writeMessageFile writes the full commit message to a temporary file for
+// 'git commit -F'. The caller removes the file.
+func writeMessageFile(message string) (string, error) {
+	file, err := os.CreateTemp("", "snippety-msg-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit message file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(message); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write commit message file: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// commitTemplate returns the contents of the commit.template file, or an
+// empty string when none is configured.
+func commitTemplate() (string, error) {
+	path := gitConfigPath("commit.template")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit.template: %w", err)
+	}
+	return string(data), nil
+}
+
+// applyCommitTemplate merges the non-comment content of a commit template
+// into msg: "Token: value" lines become the first trailers, and any other
+// text that is real content rather than instructions for the author is
+// added to the description.
+func applyCommitTemplate(msg ollama.CommitMessage, template, comment string) ollama.CommitMessage {
+	var text []string
+	var trailers []string
+	for _, line := range strings.Split(template, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		switch {
+		case strings.HasPrefix(line, comment):
+		case templatePlaceholderPattern.MatchString(line):
+		case templateTrailerPattern.MatchString(line):
+			// "Ticket: <id>" is a placeholder as well
+			if _, value, _ := strings.Cut(line, ": "); !templateBoilerplate(value) {
+				trailers = append(trailers, line)
+			}
+		case templateBoilerplate(line):
+		default:
+			text = append(text, line)
+		}
+	}
+
+	if body := strings.TrimSpace(strings.Join(text, "\n")); body != "" {
+		msg.Description = strings.TrimSpace(msg.Description + "\n\n" + body)
+	}
+	// The sign-off stays last
+	msg.Trailers = mergeTrailers(trailers, msg.Trailers)
+	return msg
+}
+
+// templateBoilerplate reports whether a template line only guides the
+// author and should not end up in the commit message.
+func templateBoilerplate(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, pattern := range templateBoilerplatePatterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderCommitMessage applies commit.template and the body width of opts to
+// msg and renders it as a commit message.
+func renderCommitMessage(msg ollama.CommitMessage, opts CommitOptions) (string, error) {
+	template, err := commitTemplate()
+	if err != nil {
+		return "", err
+	}
+	return formatCommitMessage(wrapMessage(applyCommitTemplate(msg, template, commentChar()), opts.Wrap)), nil
+}
+
+// commentChar returns the configured core.commentChar. With "auto" git
+// picks a character per message, so the default is assumed here.
+func commentChar() string {
+	char := gitConfig("core.commentChar")
+	if char == "" || char == "auto" {
+		return "#"
+	}
+	return char
+}
+
+// safeCommentChar returns preferred unless a line of message starts with
+// it, in which case --cleanup=strip would drop that line and the first
+// unused candidate is returned instead.
+func safeCommentChar(message, preferred string) string {
+	used := func(char string) bool {
+		for _, line := range strings.Split(message, "\n") {
+			if strings.HasPrefix(line, char) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !used(preferred) {
+		return preferred
+	}
+	for _, candidate := range commentCharCandidates {
+		if !used(string(candidate)) {
+			return string(candidate)
+		}
+	}
+	return preferred
+}
+
+// gitConfigPath returns a path valued git config key with ~ expanded.
+func gitConfigPath(key string) string {
+	output, err := exec.Command("git", "config", "--path", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,111 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+func TestApplyCommitTemplate(t *testing.T) {
+	template := `
+# Why is this change needed?
+Ticket:
+
+Reviewed-by: Platform Team <platform@example.com>
+# Any breaking changes?
+`
+	msg := ollama.CommitMessage{
+		Title:       "Add login",
+		Description: "Adds a login form.",
+		Trailers:    []string{"Signed-off-by: A <a@b>"},
+	}
+
+	result := applyCommitTemplate(msg, template, "#")
+
+	if result.Description != "Adds a login form." {
+		t.Errorf("Description = %q, want %q", result.Description, "Adds a login form.")
+	}
+	expected := []string{"Reviewed-by: Platform Team <platform@example.com>", "Signed-off-by: A <a@b>"}
+	if !reflect.DeepEqual(result.Trailers, expected) {
+		t.Errorf("Trailers = %q, want %q", result.Trailers, expected)
+	}
+}
+
+func TestApplyCommitTemplateKeepsText(t *testing.T) {
+	template := "; Describe the change\nPart of the 2024 migration.\n"
+	msg := ollama.CommitMessage{Title: "Add login", Description: "Adds a login form."}
+
+	result := applyCommitTemplate(msg, template, ";")
+
+	expected := "Adds a login form.\n\nPart of the 2024 migration."
+	if result.Description != expected {
+		t.Errorf("Description = %q, want %q", result.Description, expected)
+	}
+	if len(result.Trailers) != 0 {
+		t.Errorf("Trailers = %q, want none", result.Trailers)
+	}
+}
+
+func TestApplyCommitTemplateSkipsBoilerplate(t *testing.T) {
+	template := `<Summary of the change>
+
+Why is this change needed?
+Details:
+-----
+Describe the impact on users here.
+Explain any trade-offs...
+[ticket]
+Part of the 2024 migration.
+Ticket: <JIRA-ID>
+Reviewed-by: Platform Team <platform@example.com>
+`
+	msg := ollama.CommitMessage{Title: "Add login", Description: "Adds a login form."}
+
+	result := applyCommitTemplate(msg, template, "#")
+
+	expected := "Adds a login form.\n\nPart of the 2024 migration."
+	if result.Description != expected {
+		t.Errorf("Description = %q, want %q", result.Description, expected)
+	}
+	trailers := []string{"Reviewed-by: Platform Team <platform@example.com>"}
+	if !reflect.DeepEqual(result.Trailers, trailers) {
+		t.Errorf("Trailers = %q, want %q", result.Trailers, trailers)
+	}
+}
+
+func TestSafeCommentChar(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		preferred string
+		expected  string
+	}{
+		{
+			name:      "Unused comment char",
+			message:   "Fix #123\n\nCloses issue #123.\n",
+			preferred: "#",
+			expected:  "#",
+		},
+		{
+			name:      "Line starting with comment char",
+			message:   "Fix crash\n\n#123 was caused by a nil map.\n",
+			preferred: "#",
+			expected:  ";",
+		},
+		{
+			name:      "Custom comment char in use",
+			message:   "Fix crash\n\n; is now allowed in names\n#1 and @me\n",
+			preferred: ";",
+			expected:  "@",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := safeCommentChar(tt.message, tt.preferred); result != tt.expected {
+				t.Errorf("safeCommentChar() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
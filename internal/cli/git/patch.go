@@ -0,0 +1,163 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fileDiff is the portion of a unified diff that belongs to a single file.
+type fileDiff struct {
+	Path   string
+	Header []string // "diff --git" line up to (not including) the first hunk
+	Hunks  []hunk
+}
+
+// hunk is a single "@@ ... @@" section of a file diff.
+type hunk struct {
+	Header string
+	Lines  []string
+}
+
+// parseDiff splits a unified diff as produced by git into per-file sections.
+func parseDiff(diff string) []fileDiff {
+	var files []fileDiff
+	var current *fileDiff
+
+	lines := strings.Split(diff, "\n")
+	// A trailing newline yields an empty last element which is not part of the diff
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			files = append(files, fileDiff{Path: diffPath(line), Header: []string{line}})
+			current = &files[len(files)-1]
+		case current == nil:
+			// preamble before the first file, e.g. from git show
+		case strings.HasPrefix(line, "@@"):
+			current.Hunks = append(current.Hunks, hunk{Header: line})
+		case len(current.Hunks) == 0:
+			current.Header = append(current.Header, line)
+			if path, ok := headerPath(line); ok {
+				current.Path = path
+			}
+		default:
+			last := &current.Hunks[len(current.Hunks)-1]
+			last.Lines = append(last.Lines, line)
+		}
+	}
+
+	return files
+}
+
+// diffPath extracts the destination path from a "diff --git a/x b/x" line.
+// Paths with spaces are only unambiguous there when both sides are equal, so
+// parseDiff prefers the path from the "+++" or "rename to" header lines.
+func diffPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+
+	// Paths with special characters are quoted, e.g. "b/caf\303\251.go"
+	if strings.HasSuffix(rest, `"`) {
+		if idx := strings.LastIndex(rest, ` "b/`); idx >= 0 {
+			return strings.TrimPrefix(unquotePath(rest[idx+1:]), "b/")
+		}
+	}
+
+	// "a/<path> b/<path>" with the same path on both sides
+	if n := len(rest); n%2 == 1 {
+		half := (n - 1) / 2
+		if rest[half] == ' ' && strings.HasPrefix(rest, "a/") && rest[half+1:half+3] == "b/" && rest[2:half] == rest[half+3:] {
+			return rest[half+3:]
+		}
+	}
+
+	if idx := strings.LastIndex(rest, " b/"); idx >= 0 {
+		return rest[idx+len(" b/"):]
+	}
+	return ""
+}
+
+// headerPath returns the destination path named by a "+++ b/x" or
+// "rename to x" file header line.
+func headerPath(line string) (string, bool) {
+	switch {
+	case strings.HasPrefix(line, "+++ "):
+		// git appends a tab to names containing spaces
+		path := strings.TrimSuffix(strings.TrimPrefix(line, "+++ "), "\t")
+		if path == "/dev/null" {
+			return "", false
+		}
+		return strings.TrimPrefix(unquotePath(path), "b/"), true
+	case strings.HasPrefix(line, "rename to "):
+		return unquotePath(strings.TrimPrefix(line, "rename to ")), true
+	}
+	return "", false
+}
+
+// unquotePath undoes git's C-style quoting of unusual path names.
+func unquotePath(path string) string {
+	if !strings.HasPrefix(path, `"`) {
+		return path
+	}
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
+
+// String renders the file diff back into unified diff format.
+func (f fileDiff) String() string {
+	var b strings.Builder
+	for _, line := range f.Header {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	for _, h := range f.Hunks {
+		b.WriteString(h.String())
+	}
+	return b.String()
+}
+
+// String renders the hunk back into unified diff format.
+func (h hunk) String() string {
+	var b strings.Builder
+	b.WriteString(h.Header)
+	b.WriteString("\n")
+	for _, line := range h.Lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// joinFileDiffs renders several file diffs into a single patch.
+func joinFileDiffs(files []fileDiff) string {
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString(f.String())
+	}
+	return b.String()
+}
+
+// diffStats summarizes files like the last line of 'git diff --stat'.
+func diffStats(files []fileDiff) string {
+	var added, removed int
+	for _, f := range files {
+		for _, h := range f.Hunks {
+			a, r := countHunkLines(h)
+			added += a
+			removed += r
+		}
+	}
+
+	plural := func(n int, word string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, word)
+		}
+		return fmt.Sprintf("%d %ss", n, word)
+	}
+	return fmt.Sprintf("%s changed, %s(+), %s(-)", plural(len(files), "file"), plural(added, "insertion"), plural(removed, "deletion"))
+}
@@ -0,0 +1,99 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1234567..7890abc 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
+ 
++import "fmt"
+@@ -10,2 +11,2 @@ func main() {
+-	println("hi")
++	fmt.Println("hi")
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..1234567
+Binary files /dev/null and b/logo.png differ
+`
+
+	files := parseDiff(diff)
+	if len(files) != 2 {
+		t.Fatalf("parseDiff() returned %d files, want 2", len(files))
+	}
+
+	if files[0].Path != "main.go" {
+		t.Errorf("files[0].Path = %q, want %q", files[0].Path, "main.go")
+	}
+	if len(files[0].Hunks) != 2 {
+		t.Errorf("len(files[0].Hunks) = %d, want 2", len(files[0].Hunks))
+	}
+	if files[1].Path != "logo.png" || len(files[1].Hunks) != 0 {
+		t.Errorf("files[1] = %q with %d hunks, want logo.png with 0 hunks", files[1].Path, len(files[1].Hunks))
+	}
+
+	if result := joinFileDiffs(files); result != diff {
+		t.Errorf("joinFileDiffs(parseDiff(diff)) did not round-trip:\n%s", result)
+	}
+}
+
+func TestParseDiffEmpty(t *testing.T) {
+	if files := parseDiff(""); len(files) != 0 {
+		t.Errorf("parseDiff(\"\") = %v, want no files", files)
+	}
+}
+
+func TestDiffPath(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{"diff --git a/main.go b/main.go", "main.go"},
+		{"diff --git a/docs/my notes.md b/docs/my notes.md", "docs/my notes.md"},
+		{"diff --git a/a b/c.txt b/a b/c.txt", "a b/c.txt"},
+		{`diff --git "a/caf\303\251.go" "b/caf\303\251.go"`, "café.go"},
+		{"diff --git a/old.go b/new.go", "new.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if result := diffPath(tt.line); result != tt.expected {
+				t.Errorf("diffPath(%q) = %q, want %q", tt.line, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDiffHeaderPaths(t *testing.T) {
+	diff := "diff --git a/old name.txt b/new name.txt\n" +
+		"similarity index 90%\n" +
+		"rename from old name.txt\n" +
+		"rename to new name.txt\n" +
+		"--- a/old name.txt\t\n" +
+		"+++ b/new name.txt\t\n" +
+		"@@ -1 +1 @@\n" +
+		"-a\n" +
+		"+b\n" +
+		"diff --git a/gone file.txt b/gone file.txt\n" +
+		"deleted file mode 100644\n" +
+		"--- a/gone file.txt\t\n" +
+		"+++ /dev/null\n" +
+		"@@ -1 +0,0 @@\n" +
+		"-a\n"
+
+	files := parseDiff(diff)
+	if len(files) != 2 {
+		t.Fatalf("parseDiff() returned %d files, want 2", len(files))
+	}
+	if files[0].Path != "new name.txt" {
+		t.Errorf("files[0].Path = %q, want %q", files[0].Path, "new name.txt")
+	}
+	if files[1].Path != "gone file.txt" {
+		t.Errorf("files[1].Path = %q, want %q", files[1].Path, "gone file.txt")
+	}
+}
@@ -0,0 +1,209 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// baseCandidates are tried in order when no base branch is given.
+var baseCandidates = []string{"origin/HEAD", "main", "master", "origin/main", "origin/master", "upstream/main", "upstream/master"}
+
+// GeneratePullRequest writes a pull request title and markdown body for the
+// commits between the current branch and its base, to stdout or outputFile.
+// The models of the chain are tried in order.
+func GeneratePullRequest(ollamaURL, ollamaModel string, fallbackModels []string, base, templateFile, outputFile string) {
+	// Without an output file the description goes to stdout, so diagnostics
+	// go to stderr to keep 'snippety pr > pr.md' clean
+	diag := io.Writer(os.Stdout)
+	if outputFile == "" {
+		diag = os.Stderr
+	}
+
+	base, mergeBase, err := findMergeBase(base)
+	if err != nil {
+		fmt.Fprintf(diag, "%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	logrus.WithField("base", base).WithField("merge-base", mergeBase).Debug("found pull request base")
+
+	commitRange := mergeBase + "..HEAD"
+	subjects, err := getCommitSubjects(commitRange)
+	if err != nil {
+		fmt.Fprintf(diag, "%sError reading commits: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	if len(subjects) == 0 {
+		fmt.Fprintf(diag, "%sNo commits found between %s and HEAD.%s\n", ColorYellow, base, ColorReset)
+		return
+	}
+
+	commits, err := getCommitLog(commitRange)
+	if err != nil {
+		fmt.Fprintf(diag, "%sError reading commits: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	diff, err := runGitDiff("diff", mergeBase, "HEAD")
+	if err != nil {
+		fmt.Fprintf(diag, "%sError getting diff: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	var template string
+	if templateFile != "" {
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			fmt.Fprintf(diag, "%sError reading template: %v%s\n", ColorRed, err, ColorReset)
+			return
+		}
+		template = string(data)
+	}
+
+	ticketPrefix := currentTicketPrefix(diag)
+	ticket := strings.TrimSuffix(ticketPrefix, ": ")
+
+	chain := newModelChain(ollamaURL, ollamaModel, fallbackModels)
+	chain.out = diag
+	ctx := context.Background()
+
+	pr := fallbackPullRequest(subjects, ticket, template)
+	if chain.available(ctx) {
+		chain.run("pull request", func(client *ollama.Client) error {
+			generated, err := client.GeneratePullRequest(ctx, commits, diff, ticket, template)
+			if err == nil {
+				pr = generated
+			}
+			return err
+		})
+	}
+	pr.Title = ticketPrefix + strings.TrimPrefix(pr.Title, ticketPrefix)
+
+	output := pr.Title + "\n\n" + pr.Body + "\n"
+	if outputFile == "" {
+		fmt.Print(output)
+		return
+	}
+
+	if err := os.WriteFile(outputFile, []byte(output), 0o644); err != nil {
+		fmt.Fprintf(diag, "%sError writing %s: %v%s\n", ColorRed, outputFile, err, ColorReset)
+		return
+	}
+	fmt.Fprintf(diag, "%s✅ Pull request description written to %s%s\n", ColorGreen, outputFile, ColorReset)
+}
+
+// findMergeBase returns the base branch (the given one or the first existing
+// candidate) and its merge-base with HEAD.
+func findMergeBase(base string) (string, string, error) {
+	candidates := baseCandidates
+	if base != "" {
+		candidates = []string{base}
+	}
+
+	for _, candidate := range candidates {
+		if _, err := resolveRevision(candidate); err != nil {
+			continue
+		}
+		output, err := exec.Command("git", "merge-base", candidate, "HEAD").Output()
+		if err != nil {
+			continue
+		}
+		return candidate, strings.TrimSpace(string(output)), nil
+	}
+
+	if base != "" {
+		return "", "", fmt.Errorf("could not find a merge-base between '%s' and HEAD", base)
+	}
+	return "", "", fmt.Errorf("could not detect the base branch, tried %s; use --base", strings.Join(baseCandidates, ", "))
+}
+
+// getCommitSubjects returns the subjects of the commits in commitRange,
+// oldest first.
+func getCommitSubjects(commitRange string) ([]string, error) {
+	output, err := exec.Command("git", "log", "--reverse", "--format=%s", commitRange).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	var subjects []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
+// getCommitLog returns the full messages of the commits in commitRange,
+// oldest first, for use as model context.
+func getCommitLog(commitRange string) (string, error) {
+	output, err := exec.Command("git", "log", "--reverse", "--format=commit %h%n%B", commitRange).Output()
+	if err != nil {
+		return "", fmt.Errorf("git log failed: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// fallbackPullRequest builds a pull request from the commit subjects alone,
+// using the template's section headings when one is given.
+func fallbackPullRequest(subjects []string, ticket, template string) ollama.PullRequest {
+	title := subjects[0]
+	if len(subjects) > 1 {
+		title = fmt.Sprintf("%s (+%d more)", subjects[0], len(subjects)-1)
+	}
+
+	var changes strings.Builder
+	for _, subject := range subjects {
+		changes.WriteString("- " + subject + "\n")
+	}
+
+	headings := templateHeadings(template)
+	if len(headings) == 0 {
+		headings = []string{"## Summary", "## Changes", "## Testing"}
+	}
+
+	var body strings.Builder
+	for i, heading := range headings {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		body.WriteString(heading + "\n")
+
+		name := strings.ToLower(strings.TrimSpace(strings.TrimLeft(heading, "#")))
+		switch {
+		case strings.Contains(name, "change"):
+			body.WriteString(changes.String())
+		case strings.Contains(name, "summary") || strings.Contains(name, "description"):
+			fmt.Fprintf(&body, "This branch contains %d commit(s).\n", len(subjects))
+		case strings.Contains(name, "ticket") || strings.Contains(name, "issue"):
+			if ticket != "" {
+				body.WriteString(ticket + "\n")
+			}
+		}
+	}
+
+	if ticket != "" && !strings.Contains(body.String(), ticket) {
+		fmt.Fprintf(&body, "\nTicket: %s\n", ticket)
+	}
+
+	return ollama.PullRequest{Title: title, Body: strings.TrimSpace(body.String())}
+}
+
+// templateHeadings returns the markdown heading lines of a template.
+func templateHeadings(template string) []string {
+	var headings []string
+	for _, line := range strings.Split(template, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			headings = append(headings, line)
+		}
+	}
+	return headings
+}
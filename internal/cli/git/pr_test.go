@@ -0,0 +1,144 @@
+package git
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+func TestFallbackPullRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		subjects      []string
+		ticket        string
+		template      string
+		expectedTitle string
+		expectedBody  string
+	}{
+		{
+			name:          "Single commit with default sections",
+			subjects:      []string{"Add login form"},
+			expectedTitle: "Add login form",
+			expectedBody:  "## Summary\nThis branch contains 1 commit(s).\n\n## Changes\n- Add login form\n\n## Testing",
+		},
+		{
+			name:          "Template headings and ticket",
+			subjects:      []string{"Add login form", "Fix typo"},
+			ticket:        "AUTH-12",
+			template:      "## What changed\n<!-- describe -->\n## Related issue\n",
+			expectedTitle: "Add login form (+1 more)",
+			expectedBody:  "## What changed\n- Add login form\n- Fix typo\n\n## Related issue\nAUTH-12",
+		},
+		{
+			name:          "Ticket appended when template has no ticket section",
+			subjects:      []string{"Fix crash"},
+			ticket:        "BUG-7",
+			template:      "## Notes",
+			expectedTitle: "Fix crash",
+			expectedBody:  "## Notes\n\nTicket: BUG-7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := fallbackPullRequest(tt.subjects, tt.ticket, tt.template)
+			if result.Title != tt.expectedTitle {
+				t.Errorf("fallbackPullRequest().Title = %q, want %q", result.Title, tt.expectedTitle)
+			}
+			if result.Body != tt.expectedBody {
+				t.Errorf("fallbackPullRequest().Body = %q, want %q", result.Body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+// captureOutput runs fn and returns what it wrote to stdout and stderr.
+func captureOutput(t *testing.T, fn func()) (string, string) {
+	t.Helper()
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdoutW, stderrW
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	var outBuf, errBuf bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&outBuf, stdoutR)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(&errBuf, stderrR)
+		done <- struct{}{}
+	}()
+
+	fn()
+	stdoutW.Close()
+	stderrW.Close()
+	<-done
+	<-done
+	return outBuf.String(), errBuf.String()
+}
+
+func TestGeneratePullRequestKeepsStdoutClean(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "a.txt", "a\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	// The branch does not carry a ticket, which prints a warning
+	git("checkout", "-q", "-b", "cleanup")
+	writeTestFile(t, "b.txt", "b\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "Add b")
+
+	ollama.SetTimeouts(ollama.DefaultTimeouts(), ollama.RetryPolicy{})
+	defer ollama.SetTimeouts(ollama.DefaultTimeouts(), ollama.DefaultRetryPolicy())
+
+	stdout, stderr := captureOutput(t, func() {
+		GeneratePullRequest("http://127.0.0.1:1", "llama3.2", nil, "main", "", "")
+	})
+
+	if !strings.HasPrefix(stdout, "Add b\n\n## Summary") {
+		t.Errorf("stdout should only hold the pull request, got:\n%s", stdout)
+	}
+	if !strings.Contains(stderr, "does not match ticket pattern") || !strings.Contains(stderr, "health check failed") {
+		t.Errorf("diagnostics should go to stderr, got:\n%s", stderr)
+	}
+}
+
+func TestGeneratePullRequestUsesFallbackModel(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "a.txt", "a\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("checkout", "-q", "-b", "cleanup")
+	writeTestFile(t, "b.txt", "b\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "Add b")
+
+	server := chainServer(t, []string{"llama3.2:3b"}, map[string]string{
+		"llama3.2:3b": "TITLE: Add the b file\nBODY:\n## Summary\nAdds b.",
+	})
+	defer server.Close()
+
+	stdout, _ := captureOutput(t, func() {
+		GeneratePullRequest(server.URL, "qwen2.5-coder:7b", []string{"llama3.2:3b"}, "main", "", "")
+	})
+
+	if !strings.HasPrefix(stdout, "Add the b file\n\n## Summary\nAdds b.") {
+		t.Errorf("the fallback model should write the pull request, got:\n%s", stdout)
+	}
+}
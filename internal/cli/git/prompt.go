@@ -0,0 +1,47 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// ShowPrompt prints the commit message prompt for the staged changes as it
+// would be sent to the model, without calling it.
+func ShowPrompt(tone, language string, learnStyle bool) {
+	diff, err := getStagedDiff()
+	if err != nil {
+		fmt.Printf("%sError getting staged diff: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	if strings.TrimSpace(diff) == "" {
+		fmt.Printf("%sNo staged changes found. Please stage your changes with 'git add' first.%s\n", ColorYellow, ColorReset)
+		return
+	}
+
+	ticketPrefix := ""
+	if branch, err := getCurrentBranch(); err == nil {
+		ticketPrefix = extractTicketPrefix(branch)
+	}
+
+	pc := diffPromptContext(diff, ticketPrefix)
+	pc.State = detectRepoState().promptContext()
+	pc.Language = language
+	if learnStyle {
+		style, err := learnedCommitStyle()
+		if err != nil {
+			fmt.Printf("%sWarning: could not learn the commit style: %v%s\n", ColorYellow, err, ColorReset)
+		} else {
+			pc.Style = style.instructions()
+			pc.Examples = style.Examples
+		}
+	}
+
+	prompt, err := ollama.RenderCommitPrompt(diff, tone, pc)
+	if err != nil {
+		fmt.Printf("%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	fmt.Println(prompt)
+}
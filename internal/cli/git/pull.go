@@ -0,0 +1,94 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// pullBarWidth is the number of characters in the download progress bar.
+const pullBarWidth = 30
+
+// PullModel makes sure model is installed on the Ollama server, pulling it
+// with a progress bar when it is missing. It reports whether the model is
+// available afterwards. Progress and errors are written to w.
+func PullModel(ollamaURL, model string, w io.Writer) bool {
+	client := ollama.NewClient(ollamaURL, model)
+	err := client.HealthCheck(context.Background())
+	if err == nil {
+		logrus.WithField("model", model).Debug("model already installed")
+		return true
+	}
+	if !ollama.IsModelNotFound(err) {
+		fmt.Fprintf(w, "%sError checking for model %s: %v%s\n", ColorRed, model, err, ColorReset)
+		return false
+	}
+
+	fmt.Fprintf(w, "Model %s is not installed, pulling it...\n", model)
+	progress := &pullProgress{out: w}
+	err = client.Pull(context.Background(), progress.print)
+	progress.finish()
+	if err != nil {
+		fmt.Fprintf(w, "%sError pulling model %s: %v%s\n", ColorRed, model, err, ColorReset)
+		return false
+	}
+	fmt.Fprintf(w, "%s✅ Model %s pulled successfully!%s\n", ColorGreen, model, ColorReset)
+	return true
+}
+
+// pullProgress prints pull status updates, redrawing the current line while
+// the status stays the same.
+type pullProgress struct {
+	out    io.Writer
+	status string
+}
+
+func (p *pullProgress) print(update ollama.PullProgress) {
+	if update.Status != p.status && p.status != "" {
+		fmt.Fprintln(p.out)
+	}
+	p.status = update.Status
+
+	if update.Total > 0 {
+		fmt.Fprintf(p.out, "\r%s %s\033[K", update.Status, progressBar(update.Completed, update.Total))
+	} else {
+		fmt.Fprintf(p.out, "\r%s\033[K", update.Status)
+	}
+}
+
+func (p *pullProgress) finish() {
+	if p.status != "" {
+		fmt.Fprintln(p.out)
+	}
+}
+
+// progressBar renders completed out of total as a bar with a percentage and
+// the transferred size.
+func progressBar(completed, total int64) string {
+	if completed > total {
+		completed = total
+	}
+	filled := int(completed * pullBarWidth / total)
+	percent := completed * 100 / total
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", pullBarWidth-filled)
+	return fmt.Sprintf("[%s] %3d%% %s/%s", bar, percent, formatBytes(completed), formatBytes(total))
+}
+
+// formatBytes renders n in the largest binary unit that keeps it above one.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
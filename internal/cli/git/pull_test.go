@@ -0,0 +1,58 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		completed int64
+		total     int64
+		expected  string
+	}{
+		{completed: 0, total: 2048, expected: "[                              ]   0% 0 B/2.0 KiB"},
+		{completed: 1024, total: 2048, expected: "[===============               ]  50% 1.0 KiB/2.0 KiB"},
+		{completed: 3 << 30, total: 3 << 30, expected: "[==============================] 100% 3.0 GiB/3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		result := progressBar(tt.completed, tt.total)
+		if result != tt.expected {
+			t.Errorf("progressBar(%d, %d) = %q, want %q", tt.completed, tt.total, result, tt.expected)
+		}
+	}
+}
+
+func TestPullModelWritesToWriter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			json.NewEncoder(w).Encode(ollama.TagsResponse{})
+		case "/api/pull":
+			fmt.Fprintln(w, `{"status":"pulling manifest"}`)
+			fmt.Fprintln(w, `{"status":"success"}`)
+		}
+	}))
+	defer server.Close()
+
+	var progress bytes.Buffer
+	var pulled bool
+	stdout, stderr := captureOutput(t, func() {
+		pulled = PullModel(server.URL, "llama3.2", &progress)
+	})
+
+	if !pulled || !strings.Contains(progress.String(), "pulled successfully") {
+		t.Errorf("PullModel() = %v, progress:\n%s", pulled, progress.String())
+	}
+	if stdout != "" || stderr != "" {
+		t.Errorf("PullModel() should only write to its writer, got stdout %q and stderr %q", stdout, stderr)
+	}
+}
@@ -0,0 +1,250 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+const (
+	// indexCommitLimit is how many recent commits are kept in the index
+	indexCommitLimit = 500
+	// indexBatchSize is how many diffs are embedded per request
+	indexBatchSize = 16
+	// inlineIndexLimit is how many new commits generating with --similar
+	// embeds before answering, larger backlogs are left to 'snippety index'
+	inlineIndexLimit = 2 * indexBatchSize
+	// maxEmbedChars keeps diffs within the context of small embedding models
+	maxEmbedChars = 4000
+	// indexFile is the vector store location inside the git directory
+	indexFile = "snippety/embeddings.json"
+)
+
+// commitIndex is the on-disk vector store of past commit diffs.
+type commitIndex struct {
+	// Model is the embedding model the vectors were computed with
+	Model   string       `json:"model"`
+	Entries []indexEntry `json:"entries"`
+}
+
+// indexEntry is the embedding of one commit's diff and its message.
+type indexEntry struct {
+	SHA     string    `json:"sha"`
+	Message string    `json:"message"`
+	Vector  []float32 `json:"vector"`
+}
+
+// errIndexCold is returned by similarCommitMessages when there is no index
+// yet and building it would hold up generation for too long.
+var errIndexCold = errors.New("no commits are indexed yet, run 'snippety index' first")
+
+// similarCommitMessages returns the messages of the k past commits whose
+// diffs are most similar to diff, indexing up to inlineIndexLimit new
+// commits first.
+func similarCommitMessages(ollamaURL, embedModel, diff string, k int, w io.Writer) ([]string, error) {
+	client := ollama.NewClient(ollamaURL, embedModel)
+	ctx := context.Background()
+
+	index, err := updateCommitIndex(ctx, client, inlineIndexLimit, w)
+	if err != nil {
+		return nil, err
+	}
+	if len(index.Entries) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := client.Embed(ctx, []string{truncateForEmbedding(diff)})
+	if err != nil {
+		return nil, err
+	}
+	return index.nearest(vectors[0], k), nil
+}
+
+// IndexCommits builds or refreshes the vector store of past commits.
+func IndexCommits(ollamaURL, embedModel string) {
+	client := ollama.NewClient(ollamaURL, embedModel)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	index, err := updateCommitIndex(ctx, client, 0, os.Stdout)
+	if err != nil {
+		fmt.Printf("%sError indexing commits: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	fmt.Printf("%s✅ %d commits indexed with %s%s\n", ColorGreen, len(index.Entries), embedModel, ColorReset)
+}
+
+// updateCommitIndex loads the index, embeds recent human commits that are
+// not in it yet and saves it again. A positive limit caps how many commits
+// are embedded, newest first; an empty index with more new commits than
+// that is not built at all and errIndexCold is returned.
+func updateCommitIndex(ctx context.Context, client *ollama.Client, limit int, w io.Writer) (commitIndex, error) {
+	path, err := gitPath(indexFile)
+	if err != nil {
+		return commitIndex{}, err
+	}
+
+	index := readCommitIndex(path)
+	if index.Model != client.Model {
+		index = commitIndex{Model: client.Model}
+	}
+
+	commits, err := readRecentCommits(indexCommitLimit)
+	if err != nil {
+		return index, err
+	}
+
+	known := map[string]bool{}
+	for _, entry := range index.Entries {
+		known[entry.SHA] = true
+	}
+	var pending []commitInfo
+	for _, commit := range humanCommits(commits) {
+		if !known[commit.SHA] {
+			pending = append(pending, commit)
+		}
+	}
+	if len(pending) == 0 {
+		return index, nil
+	}
+	if limit > 0 && len(pending) > limit {
+		if len(index.Entries) == 0 {
+			return index, errIndexCold
+		}
+		logrus.WithField("pending", len(pending)-limit).Debug("leaving older commits for 'snippety index'")
+		pending = pending[:limit]
+	}
+
+	fmt.Fprintf(w, "Indexing %d commit(s) for retrieval...\n", len(pending))
+	for start := 0; start < len(pending); start += indexBatchSize {
+		batch := pending[start:min(start+indexBatchSize, len(pending))]
+
+		inputs := make([]string, 0, len(batch))
+		for _, commit := range batch {
+			diff, err := getRevisionDiff(commit.SHA)
+			if err != nil {
+				return index, err
+			}
+			inputs = append(inputs, truncateForEmbedding(diff))
+		}
+
+		vectors, err := client.Embed(ctx, inputs)
+		if err != nil {
+			// Keep what has been indexed so far
+			if saveErr := writeCommitIndex(path, index); saveErr != nil {
+				logrus.WithError(saveErr).Warn("could not save commit index")
+			}
+			return index, err
+		}
+		for i, commit := range batch {
+			index.Entries = append(index.Entries, indexEntry{SHA: commit.SHA, Message: commitText(commit), Vector: vectors[i]})
+		}
+	}
+
+	// Drop commits that fell out of the window or were rewritten
+	recent := map[string]bool{}
+	for _, commit := range commits {
+		recent[commit.SHA] = true
+	}
+	kept := index.Entries[:0]
+	for _, entry := range index.Entries {
+		if recent[entry.SHA] {
+			kept = append(kept, entry)
+		}
+	}
+	index.Entries = kept
+
+	return index, writeCommitIndex(path, index)
+}
+
+func readCommitIndex(path string) commitIndex {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return commitIndex{}
+	}
+	var index commitIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		logrus.WithError(err).Debug("ignoring unreadable commit index")
+		return commitIndex{}
+	}
+	return index
+}
+
+func writeCommitIndex(path string, index commitIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to encode commit index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write commit index: %w", err)
+	}
+	return nil
+}
+
+// nearest returns the messages of the k entries most similar to vector.
+func (idx commitIndex) nearest(vector []float32, k int) []string {
+	type scored struct {
+		message string
+		score   float64
+	}
+	results := make([]scored, 0, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		results = append(results, scored{entry.Message, cosineSimilarity(vector, entry.Vector)})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+
+	var messages []string
+	for _, result := range results[:min(k, len(results))] {
+		messages = append(messages, result.message)
+	}
+	return messages
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// appendMissing appends the values of extra that are not in values yet.
+func appendMissing(values, extra []string) []string {
+	seen := map[string]bool{}
+	for _, value := range values {
+		seen[value] = true
+	}
+	for _, value := range extra {
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func truncateForEmbedding(diff string) string {
+	return truncateText(diff, maxEmbedChars)
+}
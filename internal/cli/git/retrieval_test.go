@@ -0,0 +1,118 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []float32
+		expected float64
+	}{
+		{name: "Identical", a: []float32{1, 2, 3}, b: []float32{1, 2, 3}, expected: 1},
+		{name: "Orthogonal", a: []float32{1, 0}, b: []float32{0, 1}, expected: 0},
+		{name: "Opposite", a: []float32{1, 1}, b: []float32{-1, -1}, expected: -1},
+		{name: "Different dimensions", a: []float32{1, 0}, b: []float32{1, 0, 0}, expected: 0},
+		{name: "Zero vector", a: []float32{0, 0}, b: []float32{1, 0}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := cosineSimilarity(tt.a, tt.b); math.Abs(result-tt.expected) > 1e-9 {
+				t.Errorf("cosineSimilarity() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAppendMissing(t *testing.T) {
+	result := appendMissing([]string{"a", "b"}, []string{"b", "c", "a", "c"})
+	if !reflect.DeepEqual(result, []string{"a", "b", "c"}) {
+		t.Errorf("appendMissing() = %q, want [a b c]", result)
+	}
+}
+
+func TestCommitIndexNearest(t *testing.T) {
+	index := commitIndex{Entries: []indexEntry{
+		{SHA: "a", Message: "chore(deps): bump cobra", Vector: []float32{1, 0, 0}},
+		{SHA: "b", Message: "feat: add migration 042", Vector: []float32{0, 1, 0.1}},
+		{SHA: "c", Message: "feat: add migration 041", Vector: []float32{0, 1, 0.2}},
+	}}
+
+	result := index.nearest([]float32{0, 1, 0.1}, 2)
+	expected := []string{"feat: add migration 042", "feat: add migration 041"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("nearest() = %q, want %q", result, expected)
+	}
+
+	if result := index.nearest([]float32{1, 0, 0}, 10); len(result) != 3 || result[0] != "chore(deps): bump cobra" {
+		t.Errorf("nearest() = %q, want all entries with the dependency bump first", result)
+	}
+}
+
+func TestUpdateCommitIndexLimit(t *testing.T) {
+	git := newTestRepo(t)
+	for i := 0; i < inlineIndexLimit+5; i++ {
+		writeTestFile(t, "counter.txt", fmt.Sprintf("%d\n", i))
+		git("add", ".")
+		git("commit", "-q", "-m", fmt.Sprintf("count to %d", i))
+	}
+
+	var embedded int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollama.EmbedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		embedded += len(req.Input)
+		resp := ollama.EmbedResponse{}
+		for range req.Input {
+			resp.Embeddings = append(resp.Embeddings, []float32{1, 0})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+	client := ollama.NewClient(server.URL, "nomic-embed-text")
+
+	// A cold index with a long history is left to 'snippety index'
+	if _, err := updateCommitIndex(context.Background(), client, inlineIndexLimit, io.Discard); !errors.Is(err, errIndexCold) {
+		t.Fatalf("updateCommitIndex() on a cold index = %v, want errIndexCold", err)
+	}
+	if embedded != 0 {
+		t.Errorf("cold index embedded %d commits, want 0", embedded)
+	}
+
+	// Once some commits are indexed, only the newest ones are added inline
+	path := strings.TrimSpace(git("rev-parse", "--git-path", indexFile))
+	oldest := strings.TrimSpace(git("rev-list", "--max-parents=0", "HEAD"))
+	if err := writeCommitIndex(path, commitIndex{Model: "nomic-embed-text", Entries: []indexEntry{{SHA: oldest, Vector: []float32{0, 1}}}}); err != nil {
+		t.Fatal(err)
+	}
+	index, err := updateCommitIndex(context.Background(), client, inlineIndexLimit, io.Discard)
+	if err != nil {
+		t.Fatalf("updateCommitIndex() unexpected error: %v", err)
+	}
+	if embedded != inlineIndexLimit || len(index.Entries) != inlineIndexLimit+1 {
+		t.Errorf("embedded %d commits into %d entries, want %d into %d", embedded, len(index.Entries), inlineIndexLimit, inlineIndexLimit+1)
+	}
+}
+
+func TestTruncateForEmbedding(t *testing.T) {
+	diff := strings.Repeat("a", maxEmbedChars-1) + "ä"
+	result := truncateForEmbedding(diff)
+	if !utf8.ValidString(result) || len(result) != maxEmbedChars-1 {
+		t.Errorf("truncateForEmbedding() returned %d bytes, valid UTF-8 %v", len(result), utf8.ValidString(result))
+	}
+}
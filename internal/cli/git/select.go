@@ -0,0 +1,314 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// errSelectionAborted is returned when the user quits the hunk picker.
+var errSelectionAborted = errors.New("selection aborted")
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+
+// selectAndStageHunks lists the uncommitted and untracked changes, lets the
+// user pick files and hunks and stages exactly the selected ones. Changes
+// that are already staged start out selected, but the index is reset so that
+// deselected ones are not committed. It reports whether anything was staged.
+func selectAndStageHunks(w io.Writer) (bool, error) {
+	base := "HEAD"
+	if _, err := resolveRevision(base); err != nil {
+		base = emptyTree
+	}
+
+	patch, err := getWorkingTreePatch(base)
+	if err != nil {
+		return false, err
+	}
+
+	files := parseDiff(patch)
+	if len(files) == 0 {
+		return false, nil
+	}
+
+	staged, err := getStagedPatch()
+	if err != nil {
+		return false, err
+	}
+
+	selection := newHunkSelection(files)
+	selection.preselect(files, parseDiff(staged))
+	for {
+		printHunkSelection(files, selection, w)
+		fmt.Fprint(w, "\nToggle with numbers (e.g. \"1 2.3\"), 'a' all, 'n' none, 'q' quit, Enter to stage selection: ")
+		response, err := stdin.ReadString('\n')
+		if err != nil {
+			return false, fmt.Errorf("error reading input: %w", err)
+		}
+
+		response = strings.TrimSpace(response)
+		if response == "" {
+			break
+		}
+		if response == "q" {
+			return false, errSelectionAborted
+		}
+		if err := selection.apply(response); err != nil {
+			fmt.Fprintf(w, "%s%v%s\n", ColorRed, err, ColorReset)
+		}
+	}
+
+	var selected []fileDiff
+	for i, f := range files {
+		if sub, ok := selectHunks(f, selection[i]); ok {
+			selected = append(selected, sub)
+		}
+	}
+	if len(selected) == 0 {
+		return false, nil
+	}
+
+	// The selection is relative to base, so it replaces whatever was staged
+	if err := unstageAll(); err != nil {
+		return false, err
+	}
+	selectedPatch := joinFileDiffs(selected)
+	if err := applyToIndex(selectedPatch); err != nil {
+		restoreIndex(staged)
+		return false, err
+	}
+	if err := checkStaged(selectedPatch); err != nil {
+		if resetErr := unstageAll(); resetErr == nil {
+			restoreIndex(staged)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// restoreIndex stages patch again after a failed selection.
+func restoreIndex(patch string) {
+	if strings.TrimSpace(patch) == "" {
+		return
+	}
+	if err := applyToIndex(patch); err != nil {
+		logrus.WithError(err).Warn("could not restore the staged changes")
+	}
+}
+
+// hunkSelection holds one checkbox per hunk for every file. Files without
+// hunks (binary files, mode changes) have a single checkbox for the whole file.
+type hunkSelection [][]bool
+
+func newHunkSelection(files []fileDiff) hunkSelection {
+	selection := make(hunkSelection, len(files))
+	for i, f := range files {
+		selection[i] = make([]bool, max(1, len(f.Hunks)))
+	}
+	return selection
+}
+
+// preselect checks the hunks of files that are staged unchanged in staged.
+// Files without hunks are checked when their staged version is the same.
+func (s hunkSelection) preselect(files, staged []fileDiff) {
+	stagedFiles := make(map[string]fileDiff, len(staged))
+	for _, f := range staged {
+		stagedFiles[f.Path] = f
+	}
+
+	for i, f := range files {
+		stagedFile, ok := stagedFiles[f.Path]
+		if !ok {
+			continue
+		}
+		if len(f.Hunks) == 0 {
+			s[i][0] = reflect.DeepEqual(f.Header[1:], stagedFile.Header[1:])
+			continue
+		}
+		for j, h := range f.Hunks {
+			for _, stagedHunk := range stagedFile.Hunks {
+				if reflect.DeepEqual(h.Lines, stagedHunk.Lines) {
+					s[i][j] = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// apply toggles the checkboxes named in input. "3" toggles every hunk of
+// file 3, "3.2" only its second hunk; "a" and "n" select all or nothing.
+func (s hunkSelection) apply(input string) error {
+	for _, token := range strings.Fields(input) {
+		switch token {
+		case "a", "n":
+			for _, hunks := range s {
+				for j := range hunks {
+					hunks[j] = token == "a"
+				}
+			}
+			continue
+		}
+
+		fileToken, hunkToken, hasHunk := strings.Cut(token, ".")
+		fileIdx, err := strconv.Atoi(fileToken)
+		if err != nil || fileIdx < 1 || fileIdx > len(s) {
+			return fmt.Errorf("invalid selection %q", token)
+		}
+		hunks := s[fileIdx-1]
+
+		if !hasHunk {
+			all := true
+			for _, checked := range hunks {
+				all = all && checked
+			}
+			for j := range hunks {
+				hunks[j] = !all
+			}
+			continue
+		}
+
+		hunkIdx, err := strconv.Atoi(hunkToken)
+		if err != nil || hunkIdx < 1 || hunkIdx > len(hunks) {
+			return fmt.Errorf("invalid selection %q", token)
+		}
+		hunks[hunkIdx-1] = !hunks[hunkIdx-1]
+	}
+	return nil
+}
+
+func printHunkSelection(files []fileDiff, selection hunkSelection, w io.Writer) {
+	fmt.Fprintf(w, "\n%sSelect changes to stage:%s\n", ColorBold+ColorBlue, ColorReset)
+	for i, f := range files {
+		fmt.Fprintf(w, "%s %s%d%s %s%s%s\n", checkbox(selection[i]...), ColorBold, i+1, ColorReset, ColorCyan, f.Path, ColorReset)
+		for j, h := range f.Hunks {
+			added, removed := countHunkLines(h)
+			fmt.Fprintf(w, "    %s %d.%d %s %s(+%d -%d)%s\n", checkbox(selection[i][j]), i+1, j+1, h.Header, ColorYellow, added, removed, ColorReset)
+		}
+	}
+}
+
+// checkbox renders [x] when every state is checked, [~] when some are and
+// [ ] otherwise.
+func checkbox(states ...bool) string {
+	checked := 0
+	for _, s := range states {
+		if s {
+			checked++
+		}
+	}
+	switch {
+	case checked == 0:
+		return "[ ]"
+	case checked == len(states):
+		return ColorGreen + "[x]" + ColorReset
+	default:
+		return ColorYellow + "[~]" + ColorReset
+	}
+}
+
+func countHunkLines(h hunk) (added, removed int) {
+	for _, line := range h.Lines {
+		if strings.HasPrefix(line, "+") {
+			added++
+		} else if strings.HasPrefix(line, "-") {
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// selectHunks returns f reduced to the selected hunks, shifting the new-file
+// line numbers of the kept hunks to account for skipped ones. The second
+// result is false when nothing of f is selected.
+func selectHunks(f fileDiff, selected []bool) (fileDiff, bool) {
+	if len(f.Hunks) == 0 {
+		return f, len(selected) > 0 && selected[0]
+	}
+
+	sub := fileDiff{Path: f.Path, Header: f.Header}
+	skippedDelta := 0
+	for i, h := range f.Hunks {
+		added, removed := countHunkLines(h)
+		if !selected[i] {
+			skippedDelta += added - removed
+			continue
+		}
+		sub.Hunks = append(sub.Hunks, shiftHunk(h, -skippedDelta))
+	}
+	return sub, len(sub.Hunks) > 0
+}
+
+// shiftHunk moves the new-file start line of a hunk by offset lines.
+func shiftHunk(h hunk, offset int) hunk {
+	if offset == 0 {
+		return h
+	}
+	matches := hunkHeaderPattern.FindStringSubmatch(h.Header)
+	if matches == nil {
+		return h
+	}
+
+	newStart, _ := strconv.Atoi(matches[3])
+	header := fmt.Sprintf("@@ -%s", matches[1])
+	if matches[2] != "" {
+		header += "," + matches[2]
+	}
+	header += fmt.Sprintf(" +%d", newStart+offset)
+	if matches[4] != "" {
+		header += "," + matches[4]
+	}
+	header += " @@" + matches[5]
+
+	return hunk{Header: header, Lines: h.Lines}
+}
+
+// getWorkingTreePatch returns the changes of tracked files in the working
+// tree relative to base, staged or not, followed by the contents of
+// untracked files as new-file diffs. Paths are relative to the top level,
+// wherever in the working tree it runs.
+func getWorkingTreePatch(base string) (string, error) {
+	root, err := topLevel()
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("git", "diff", "--binary", base)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git diff: %w", err)
+	}
+	patch := string(output)
+
+	cmd = exec.Command("git", "ls-files", "--others", "--exclude-standard", "--full-name", "-z")
+	cmd.Dir = root
+	output, err = cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file == "" {
+			continue
+		}
+		// --no-index names the file as given, so it has to be relative to the top level
+		cmd := exec.Command("git", "diff", "--binary", "--no-index", "--", "/dev/null", file)
+		cmd.Dir = root
+		fileOutput, err := cmd.Output()
+		// --no-index exits with status 1 when the files differ
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return "", fmt.Errorf("failed to diff untracked file %s: %w", file, err)
+		}
+		patch += string(fileOutput)
+	}
+
+	return patch, nil
+}
@@ -0,0 +1,200 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHunkSelectionApply(t *testing.T) {
+	files := []fileDiff{
+		{Path: "a.go", Hunks: []hunk{{}, {}, {}}},
+		{Path: "logo.png"},
+	}
+
+	tests := []struct {
+		name     string
+		inputs   []string
+		expected hunkSelection
+		wantErr  bool
+	}{
+		{
+			name:     "Toggle single hunk",
+			inputs:   []string{"1.2"},
+			expected: hunkSelection{{false, true, false}, {false}},
+		},
+		{
+			name:     "Toggle whole file",
+			inputs:   []string{"1 2"},
+			expected: hunkSelection{{true, true, true}, {true}},
+		},
+		{
+			name:     "Partially selected file toggles to all",
+			inputs:   []string{"1.1", "1"},
+			expected: hunkSelection{{true, true, true}, {false}},
+		},
+		{
+			name:     "Fully selected file toggles to none",
+			inputs:   []string{"a", "1"},
+			expected: hunkSelection{{false, false, false}, {true}},
+		},
+		{
+			name:     "Select all then none",
+			inputs:   []string{"a n"},
+			expected: hunkSelection{{false, false, false}, {false}},
+		},
+		{
+			name:    "Out of range hunk",
+			inputs:  []string{"1.4"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid token",
+			inputs:  []string{"x"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection := newHunkSelection(files)
+			var err error
+			for _, input := range tt.inputs {
+				if err = selection.apply(input); err != nil {
+					break
+				}
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("apply() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("apply() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(selection, tt.expected) {
+				t.Errorf("selection = %v, want %v", selection, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSelectHunks(t *testing.T) {
+	f := fileDiff{
+		Path:   "main.go",
+		Header: []string{"diff --git a/main.go b/main.go"},
+		Hunks: []hunk{
+			{Header: "@@ -1,2 +1,4 @@", Lines: []string{" a", "+b", "+c", " d"}},
+			{Header: "@@ -10,3 +12,2 @@ func main() {", Lines: []string{" e", "-f", " g"}},
+			{Header: "@@ -20 +21 @@", Lines: []string{"-h", "+i"}},
+		},
+	}
+
+	sub, ok := selectHunks(f, []bool{false, true, true})
+	if !ok {
+		t.Fatal("selectHunks() reported nothing selected")
+	}
+
+	var headers []string
+	for _, h := range sub.Hunks {
+		headers = append(headers, h.Header)
+	}
+	expected := []string{"@@ -10,3 +10,2 @@ func main() {", "@@ -20 +19 @@"}
+	if !reflect.DeepEqual(headers, expected) {
+		t.Errorf("selectHunks() headers = %q, want %q", headers, expected)
+	}
+
+	if _, ok := selectHunks(f, []bool{false, false, false}); ok {
+		t.Error("selectHunks() with nothing selected should report false")
+	}
+}
+
+func TestHunkSelectionPreselect(t *testing.T) {
+	files := []fileDiff{
+		{Path: "a.go", Hunks: []hunk{{Lines: []string{"-a", "+b"}}, {Lines: []string{"-c", "+d"}}}},
+		{Path: "logo.png", Header: []string{"diff --git a/logo.png b/logo.png", "index 1234567..7890abc 100644"}},
+		{Path: "b.go", Hunks: []hunk{{Lines: []string{"+e"}}}},
+	}
+	staged := []fileDiff{
+		// Different line numbers, same change
+		{Path: "a.go", Hunks: []hunk{{Header: "@@ -20 +20 @@", Lines: []string{"-c", "+d"}}}},
+		{Path: "logo.png", Header: []string{"diff --git a/logo.png b/logo.png", "index 1234567..7890abc 100644"}},
+		// Partially staged
+		{Path: "b.go", Hunks: []hunk{{Lines: []string{"+f"}}}},
+	}
+
+	selection := newHunkSelection(files)
+	selection.preselect(files, staged)
+
+	expected := hunkSelection{{false, true}, {true}, {false}}
+	if !reflect.DeepEqual(selection, expected) {
+		t.Errorf("preselect() = %v, want %v", selection, expected)
+	}
+}
+
+func TestSelectAndStageHunksReplacesStaged(t *testing.T) {
+	git := newTestRepo(t)
+
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	writeTestFile(t, "notes.txt", strings.Join(lines, "\n")+"\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	lines[1] = "staged change"
+	writeTestFile(t, "notes.txt", strings.Join(lines, "\n")+"\n")
+	git("add", ".")
+	lines[27] = "unstaged change"
+	writeTestFile(t, "notes.txt", strings.Join(lines, "\n")+"\n")
+
+	// The staged hunk starts out selected: deselect it and pick the other one
+	stdin = bufio.NewReader(strings.NewReader("1.1 1.2\n\n"))
+	defer func() { stdin = bufio.NewReader(os.Stdin) }()
+
+	selected, err := selectAndStageHunks(io.Discard)
+	if err != nil || !selected {
+		t.Fatalf("selectAndStageHunks(io.Discard) = %v, %v, want true, nil", selected, err)
+	}
+
+	diff := git("diff", "--staged")
+	if strings.Contains(diff, "+staged change") || !strings.Contains(diff, "+unstaged change") {
+		t.Errorf("index should hold only the selected hunk:\n%s", diff)
+	}
+	if worktree := git("diff"); !strings.Contains(worktree, "+staged change") {
+		t.Errorf("deselected change should stay in the working tree:\n%s", worktree)
+	}
+}
+
+func TestSelectAndStageHunksFromSubdirectory(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "other/o.txt", "o\n")
+	writeTestFile(t, "sub/s.txt", "s\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	writeTestFile(t, "other/o.txt", "o2\n")
+	writeTestFile(t, "sub/new file.txt", "new\n")
+	t.Chdir("sub")
+
+	stdin = bufio.NewReader(strings.NewReader("a\n\n"))
+	defer func() { stdin = bufio.NewReader(os.Stdin) }()
+
+	selected, err := selectAndStageHunks(io.Discard)
+	if err != nil || !selected {
+		t.Fatalf("selectAndStageHunks(io.Discard) = %v, %v, want true, nil", selected, err)
+	}
+
+	staged := git("diff", "--staged", "--name-only")
+	if staged != "other/o.txt\nsub/new file.txt\n" {
+		t.Errorf("every change should be staged, got:\n%s", staged)
+	}
+}
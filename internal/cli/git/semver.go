@@ -0,0 +1,188 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var semverTagPattern = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)$`)
+
+// semver is a release version parsed from a tag such as "v1.2.3".
+type semver struct {
+	Prefix string
+	Major  int
+	Minor  int
+	Patch  int
+}
+
+func (v semver) String() string {
+	return fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+}
+
+func parseSemver(tag string) (semver, bool) {
+	matches := semverTagPattern.FindStringSubmatch(tag)
+	if matches == nil {
+		return semver{}, false
+	}
+	major, _ := strconv.Atoi(matches[2])
+	minor, _ := strconv.Atoi(matches[3])
+	patch, _ := strconv.Atoi(matches[4])
+	return semver{Prefix: matches[1], Major: major, Minor: minor, Patch: patch}, true
+}
+
+// Version bump levels, ordered by significance
+const (
+	bumpNone = iota
+	bumpPatch
+	bumpMinor
+	bumpMajor
+)
+
+var bumpNames = map[int]string{
+	bumpNone:  "none",
+	bumpPatch: "patch",
+	bumpMinor: "minor",
+	bumpMajor: "major",
+}
+
+// bump returns the next version for the given level. While the major version
+// is 0, breaking changes only bump the minor version.
+func (v semver) bump(level int) semver {
+	switch {
+	case level == bumpMajor && v.Major > 0:
+		return semver{Prefix: v.Prefix, Major: v.Major + 1}
+	case level == bumpMajor, level == bumpMinor:
+		return semver{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor + 1}
+	case level == bumpPatch:
+		return semver{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+	return v
+}
+
+// commitBump returns the bump level a single commit requires.
+func commitBump(commit commitInfo) int {
+	cc := parseConventionalCommit(commit.Subject, commit.Body)
+	if cc.Breaking {
+		return bumpMajor
+	}
+	switch changelogSection(cc) {
+	case "":
+		return bumpNone
+	case "Added":
+		return bumpMinor
+	}
+	return bumpPatch
+}
+
+// NextVersion computes the next semantic version from the commits since the
+// latest semver tag, explains which commits drove it and, with createTag,
+// creates an annotated tag with a generated release message.
+func NextVersion(ollamaURL, ollamaModel string, fallbackModels []string, createTag, offline bool) {
+	current, tag := latestSemverTag()
+	commitRange := "HEAD"
+	if tag != "" {
+		commitRange = tag + "..HEAD"
+	}
+
+	commits, err := readCommits(commitRange)
+	if err != nil {
+		fmt.Printf("%sError reading commits: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	level := bumpNone
+	drivers := map[int][]commitInfo{}
+	for _, commit := range commits {
+		commitLevel := commitBump(commit)
+		drivers[commitLevel] = append(drivers[commitLevel], commit)
+		level = max(level, commitLevel)
+	}
+
+	if tag == "" {
+		fmt.Printf("%sNo semver tag found, starting from %s%s\n", ColorYellow, current, ColorReset)
+	}
+	if level == bumpNone {
+		fmt.Printf("No release needed: none of the %d commit(s) since %s require a version bump.\n", len(commits), current)
+		return
+	}
+
+	next := current.bump(level)
+	fmt.Printf("%sNext version:%s %s%s%s (%s bump from %s)\n", ColorBold+ColorBlue, ColorReset, ColorGreen, next, ColorReset, bumpNames[level], current)
+	if level == bumpMajor && current.Major == 0 {
+		fmt.Println("Breaking changes bump the minor version while the major version is 0.")
+	}
+	for _, l := range []int{bumpMajor, bumpMinor, bumpPatch} {
+		if len(drivers[l]) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s%s:%s\n", ColorBold+ColorCyan, bumpNames[l], ColorReset)
+		for _, commit := range drivers[l] {
+			fmt.Printf("  %s%s%s %s\n", ColorYellow, shortSHA(commit.SHA), ColorReset, commit.Subject)
+		}
+	}
+
+	if !createTag {
+		return
+	}
+
+	entries := buildChangelogEntries(commits)
+	if !offline {
+		chain := newModelChain(ollamaURL, ollamaModel, fallbackModels)
+		ctx := context.Background()
+
+		if chain.available(ctx) {
+			entries = rewriteChangelogEntries(ctx, chain, entries)
+		}
+	}
+
+	message := renderTagMessage(next.String(), entries)
+	cmd := exec.Command("git", "tag", "--annotate", "--cleanup=verbatim", "--file=-", next.String())
+	cmd.Stdin = strings.NewReader(message)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		fmt.Printf("%sError creating tag: %v\nOutput: %s%s\n", ColorRed, err, string(output), ColorReset)
+		return
+	}
+	fmt.Printf("\n%s✅ Tag %s created successfully!%s\n", ColorGreen, next, ColorReset)
+}
+
+// latestSemverTag returns the highest semver tag reachable from HEAD, or
+// 0.0.0 with an empty tag name when there is none.
+func latestSemverTag() (semver, string) {
+	output, err := exec.Command("git", "tag", "--merged", "HEAD", "--sort=-v:refname").Output()
+	if err == nil {
+		for _, tag := range strings.Split(string(output), "\n") {
+			if v, ok := parseSemver(strings.TrimSpace(tag)); ok {
+				return v, strings.TrimSpace(tag)
+			}
+		}
+	}
+	return semver{Prefix: "v"}, ""
+}
+
+// renderTagMessage renders the annotated tag message for a release.
+func renderTagMessage(version string, entries []changelogEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Release %s\n", version)
+
+	for _, section := range changelogSections {
+		var lines []string
+		for _, entry := range entries {
+			if entry.Section != section {
+				continue
+			}
+			text := entry.Text
+			if entry.Breaking {
+				text = "BREAKING: " + text
+			}
+			lines = append(lines, "- "+text)
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&b, "\n%s:\n%s\n", section, strings.Join(lines, "\n"))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSemverBump(t *testing.T) {
+	tests := []struct {
+		tag      string
+		level    int
+		expected string
+	}{
+		{"v1.2.3", bumpMajor, "v2.0.0"},
+		{"v1.2.3", bumpMinor, "v1.3.0"},
+		{"v1.2.3", bumpPatch, "v1.2.4"},
+		{"1.2.3", bumpNone, "1.2.3"},
+		{"v0.4.1", bumpMajor, "v0.5.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag+" "+bumpNames[tt.level], func(t *testing.T) {
+			v, ok := parseSemver(tt.tag)
+			if !ok {
+				t.Fatalf("parseSemver(%q) failed", tt.tag)
+			}
+			if result := v.bump(tt.level).String(); result != tt.expected {
+				t.Errorf("bump() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseSemverRejectsNonReleaseTags(t *testing.T) {
+	for _, tag := range []string{"v1.2", "release-1.2.3", "v1.2.3-rc1", ""} {
+		if _, ok := parseSemver(tag); ok {
+			t.Errorf("parseSemver(%q) should fail", tag)
+		}
+	}
+}
+
+func TestCommitBump(t *testing.T) {
+	tests := []struct {
+		subject  string
+		body     string
+		expected int
+	}{
+		{"feat: add export", "", bumpMinor},
+		{"fix: handle nil", "", bumpPatch},
+		{"refactor!: rename config", "", bumpMajor},
+		{"fix: tighten validation", "BREAKING CHANGE: empty names are rejected", bumpMajor},
+		{"docs: update README", "", bumpNone},
+		{"Fix typo in help text", "", bumpPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			result := commitBump(commitInfo{Subject: tt.subject, Body: tt.body})
+			if result != tt.expected {
+				t.Errorf("commitBump(%q) = %s, want %s", tt.subject, bumpNames[result], bumpNames[tt.expected])
+			}
+		})
+	}
+}
+
+func TestNextVersionUsesFallbackModel(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "a.txt", "a\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "feat: add export")
+
+	server := chainServer(t, []string{"llama3.2:3b"}, map[string]string{"llama3.2:3b": "1. Users can export reports."})
+	defer server.Close()
+
+	captureOutput(t, func() {
+		NextVersion(server.URL, "qwen2.5-coder:7b", []string{"llama3.2:3b"}, true, false)
+	})
+
+	if message := git("tag", "-l", "--format=%(contents)", "v0.1.0"); !strings.Contains(message, "Users can export reports.") {
+		t.Errorf("the fallback model should write the release message, got:\n%s", message)
+	}
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// SigningError is returned when git could not sign a commit, for example
+// because the agent is locked or the signing key does not exist.
+type SigningError struct {
+	// Reason is a short explanation of why signing failed
+	Reason string
+	// Output is what git and the signing program printed
+	Output string
+}
+
+func (e *SigningError) Error() string {
+	return "commit signing failed: " + e.Reason
+}
+
+// signingFailures maps output fragments of gpg, ssh-keygen and git to the
+// reason reported to the user. The first match wins.
+var signingFailures = []struct {
+	fragment string
+	reason   string
+}{
+	{"no secret key", "the signing key was not found, check user.signingkey"},
+	{"couldn't load public key", "the signing key was not found, check user.signingkey"},
+	{"no default secret key", "no signing key is configured, set user.signingkey or pass --gpg-sign=<keyid>"},
+	{"inappropriate ioctl for device", "gpg could not ask for the passphrase, set GPG_TTY=$(tty) or unlock the agent"},
+	{"operation cancelled", "the passphrase prompt was cancelled"},
+	{"agent refused operation", "the ssh agent refused to sign, unlock the key or add it with ssh-add"},
+	{"couldn't get agent socket", "the signing agent is not running"},
+	{"gpg failed to sign the data", "gpg could not sign the commit, is the agent running and unlocked?"},
+	{"failed to write commit object", "git could not sign the commit"},
+}
+
+// signingMarkers appear in every signing related failure and keep other
+// errors from being reported as signing failures.
+var signingMarkers = []string{"gpg", "sign", "ssh", "public key"}
+
+// signingFailure returns a SigningError when output shows that signing, and
+// not something else, made the commit fail.
+func signingFailure(output string) (*SigningError, bool) {
+	lower := strings.ToLower(output)
+	related := false
+	for _, marker := range signingMarkers {
+		related = related || strings.Contains(lower, marker)
+	}
+	if !related {
+		return nil, false
+	}
+	for _, failure := range signingFailures {
+		if strings.Contains(lower, failure.fragment) {
+			return &SigningError{Reason: failure.reason, Output: output}, true
+		}
+	}
+	return nil, false
+}
+
+// signArgs returns the -S option for commands that create commits. Plain
+// 'git commit' honors commit.gpgsign by itself, but commit-tree does not,
+// so the setting is passed on explicitly.
+func (o CommitOptions) signArgs() []string {
+	if o.Sign {
+		return []string{"-S" + o.SigningKey}
+	}
+	if gitConfigBool("commit.gpgsign") {
+		return []string{"-S"}
+	}
+	return nil
+}
+
+// gitConfigBool reads a boolean git config key, treating unset as false.
+func gitConfigBool(key string) bool {
+	output, err := exec.Command("git", "config", "--type=bool", "--get", key).Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) == "true"
+}
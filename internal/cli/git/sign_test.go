@@ -0,0 +1,78 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSigningFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:     "Missing gpg key",
+			output:   "gpg: skipped \"ABCD1234\": No secret key\ngpg: signing failed: No secret key\nerror: gpg failed to sign the data\nfatal: failed to write commit object",
+			expected: "the signing key was not found, check user.signingkey",
+		},
+		{
+			name:     "Locked agent without a tty",
+			output:   "gpg: signing failed: Inappropriate ioctl for device\nerror: gpg failed to sign the data\nfatal: failed to write commit object",
+			expected: "gpg could not ask for the passphrase, set GPG_TTY=$(tty) or unlock the agent",
+		},
+		{
+			name:     "Missing ssh key",
+			output:   "error: Couldn't load public key /home/me/.ssh/missing.pub: No such file or directory?\n\nfatal: failed to write commit object",
+			expected: "the signing key was not found, check user.signingkey",
+		},
+		{
+			name:     "Unknown gpg failure",
+			output:   "error: gpg failed to sign the data\nfatal: failed to write commit object",
+			expected: "gpg could not sign the commit, is the agent running and unlocked?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err, ok := signingFailure(tt.output)
+			if !ok {
+				t.Fatalf("signingFailure() did not detect a signing failure")
+			}
+			if err.Reason != tt.expected {
+				t.Errorf("Reason = %q, want %q", err.Reason, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSigningFailureIgnoresOtherErrors(t *testing.T) {
+	for _, output := range []string{
+		"nothing to commit, working tree clean",
+		"error: pathspec 'x' did not match any file(s) known to git",
+		"fatal: could not open 'missing.txt': No such file or directory",
+	} {
+		if _, ok := signingFailure(output); ok {
+			t.Errorf("signingFailure(%q) reported a signing failure", output)
+		}
+	}
+}
+
+func TestSignArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     CommitOptions
+		expected []string
+	}{
+		{name: "Default key", opts: CommitOptions{Sign: true}, expected: []string{"-S"}},
+		{name: "Explicit key", opts: CommitOptions{Sign: true, SigningKey: "ABCD1234"}, expected: []string{"-SABCD1234"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.opts.signArgs(); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("signArgs() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
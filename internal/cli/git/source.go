@@ -0,0 +1,109 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Kinds of DiffSource
+const (
+	DiffSourceStaged   = "staged"
+	DiffSourceUnstaged = "unstaged"
+	DiffSourceRev      = "rev"
+	DiffSourceRange    = "range"
+	DiffSourcePatch    = "patch"
+)
+
+// DiffSource selects where the changes to describe are read from.
+type DiffSource struct {
+	Kind string
+	// Rev is the commit used with DiffSourceRev
+	Rev string
+	// Range is the "a..b" revision range used with DiffSourceRange
+	Range string
+	// Patch is the patch file used with DiffSourcePatch, "-" reads stdin
+	Patch string
+}
+
+// resolve fills in Kind when only one of Rev, Range or Patch was given and
+// validates that the selected kind has its argument.
+func (s DiffSource) resolve() (DiffSource, error) {
+	if s.Kind == "" || s.Kind == DiffSourceStaged {
+		switch {
+		case s.Rev != "":
+			s.Kind = DiffSourceRev
+		case s.Range != "":
+			s.Kind = DiffSourceRange
+		case s.Patch != "":
+			s.Kind = DiffSourcePatch
+		default:
+			s.Kind = DiffSourceStaged
+		}
+	}
+
+	switch s.Kind {
+	case DiffSourceStaged, DiffSourceUnstaged:
+	case DiffSourceRev:
+		if s.Rev == "" {
+			return s, fmt.Errorf("diff source '%s' requires --rev", s.Kind)
+		}
+	case DiffSourceRange:
+		if s.Range == "" {
+			return s, fmt.Errorf("diff source '%s' requires --range", s.Kind)
+		}
+	case DiffSourcePatch:
+		if s.Patch == "" {
+			return s, fmt.Errorf("diff source '%s' requires --patch", s.Kind)
+		}
+	default:
+		return s, fmt.Errorf("unknown diff source '%s', expected one of: %s, %s, %s, %s, %s",
+			s.Kind, DiffSourceStaged, DiffSourceUnstaged, DiffSourceRev, DiffSourceRange, DiffSourcePatch)
+	}
+	return s, nil
+}
+
+// readDiff returns the diff selected by the (resolved) source.
+func readDiff(s DiffSource) (string, error) {
+	switch s.Kind {
+	case DiffSourceUnstaged:
+		return runGitDiff("diff")
+	case DiffSourceRev:
+		return getRevisionDiff(s.Rev)
+	case DiffSourceRange:
+		return runGitDiff("diff", s.Range)
+	case DiffSourcePatch:
+		return readPatch(s.Patch)
+	default:
+		return getStagedDiff()
+	}
+}
+
+// getRevisionDiff returns the changes introduced by a single commit.
+func getRevisionDiff(rev string) (string, error) {
+	return runGitDiff("show", "--format=", rev)
+}
+
+func runGitDiff(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git diff: %w", err)
+	}
+	return string(output), nil
+}
+
+func readPatch(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read patch: %w", err)
+	}
+	return string(data), nil
+}
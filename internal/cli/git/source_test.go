@@ -0,0 +1,68 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestDiffSourceResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   DiffSource
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "Default is staged",
+			source:   DiffSource{},
+			expected: DiffSourceStaged,
+		},
+		{
+			name:     "Rev implies rev source",
+			source:   DiffSource{Rev: "abc123"},
+			expected: DiffSourceRev,
+		},
+		{
+			name:     "Range implies range source",
+			source:   DiffSource{Kind: DiffSourceStaged, Range: "main..HEAD"},
+			expected: DiffSourceRange,
+		},
+		{
+			name:     "Patch implies patch source",
+			source:   DiffSource{Patch: "-"},
+			expected: DiffSourcePatch,
+		},
+		{
+			name:     "Explicit unstaged",
+			source:   DiffSource{Kind: DiffSourceUnstaged},
+			expected: DiffSourceUnstaged,
+		},
+		{
+			name:    "Rev source without rev",
+			source:  DiffSource{Kind: DiffSourceRev},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown source",
+			source:  DiffSource{Kind: "stash"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.source.resolve()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resolve() expected an error, got kind %q", result.Kind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolve() unexpected error: %v", err)
+			}
+			if result.Kind != tt.expected {
+				t.Errorf("resolve().Kind = %q, want %q", result.Kind, tt.expected)
+			}
+		})
+	}
+}
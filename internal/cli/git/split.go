@@ -0,0 +1,399 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// Strategies for grouping staged files into separate commits
+const (
+	GroupByDirectory = "dir"
+	GroupByPackage   = "package"
+	GroupByModel     = "model"
+)
+
+// commitPlan is one of the commits proposed by SplitCommits.
+type commitPlan struct {
+	files   []string
+	patch   string
+	message ollama.CommitMessage
+}
+
+// SplitCommits groups the staged changes into logically related sets,
+// proposes a commit message for each set and, once confirmed, commits the
+// sets one after another.
+func SplitCommits(ollamaURL, ollamaModel string, fallbackModels []string, tone, language, groupBy string, autoStage, dryRun bool, commitOpts CommitOptions) {
+	if groupBy != GroupByDirectory && groupBy != GroupByPackage && groupBy != GroupByModel {
+		fmt.Printf("%sUnknown grouping '%s', expected one of: %s, %s, %s%s\n", ColorRed, groupBy, GroupByDirectory, GroupByPackage, GroupByModel, ColorReset)
+		return
+	}
+
+	trailers, err := commitOpts.trailers()
+	if err != nil {
+		fmt.Printf("%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	if autoStage {
+		logrus.Debug("Staging all changes...")
+		if err := stageAllChanges(); err != nil {
+			fmt.Printf("%sError staging changes: %v%s\n", ColorRed, err, ColorReset)
+			return
+		}
+	}
+
+	diff, err := getStagedDiff()
+	if err != nil {
+		fmt.Printf("%sError getting staged diff: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	patch, err := getStagedPatch()
+	if err != nil {
+		fmt.Printf("%sError getting staged diff: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	files := parseDiff(diff)
+	if len(files) == 0 {
+		fmt.Printf("%sNo staged changes found. Please stage your changes with 'git add' first.%s\n", ColorYellow, ColorReset)
+		return
+	}
+
+	// The binary patch is what gets applied, the text diff is what the model sees
+	patches := map[string]fileDiff{}
+	for _, f := range parseDiff(patch) {
+		patches[f.Path] = f
+	}
+
+	chain := newModelChain(ollamaURL, ollamaModel, fallbackModels)
+	available := chain.available(context.Background())
+
+	groups := groupFiles(chain.client(), available, files, groupBy)
+	ticketPrefix := currentTicketPrefix(os.Stdout)
+
+	plans := make([]commitPlan, 0, len(groups))
+	for i, group := range groups {
+		plan := commitPlan{}
+		var groupPatch []fileDiff
+		for _, f := range group {
+			plan.files = append(plan.files, f.Path)
+			groupPatch = append(groupPatch, planPatch(patches[f.Path], f))
+		}
+		plan.patch = joinFileDiffs(groupPatch)
+
+		groupDiff := joinFileDiffs(group)
+		pc := diffPromptContext(groupDiff, ticketPrefix)
+		pc.Language = language
+		plan.message, _ = generateMessage(chain, groupDiff, tone, ticketPrefix, pc)
+		plan.message.Trailers = mergeTrailers(plan.message.Trailers, trailers)
+
+		fmt.Printf("\n%sCommit %d/%d%s %s(%s)%s\n", ColorBold+ColorBlue, i+1, len(groups), ColorReset, ColorCyan, strings.Join(plan.files, ", "), ColorReset)
+		printCommitMessage(plan.message, os.Stdout)
+		plans = append(plans, plan)
+	}
+
+	if dryRun {
+		return
+	}
+
+	ok, err := confirm(fmt.Sprintf("\nDo you want to create these %d commits? (y/N): ", len(plans)), os.Stdout)
+	if err != nil {
+		fmt.Printf("Error reading input: %v\n", err)
+		return
+	}
+	if !ok {
+		fmt.Println("Commits not created.")
+		return
+	}
+
+	if err := commitPlans(plans, commitOpts); err != nil {
+		fmt.Printf("%sError creating commits: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	fmt.Printf("%s✅ %d commits created successfully!%s\n", ColorGreen, len(plans), ColorReset)
+}
+
+// groupFiles splits files according to the groupBy strategy. Model-assisted
+// grouping falls back to package grouping when the model is unavailable.
+func groupFiles(client *ollama.Client, available bool, files []fileDiff, groupBy string) [][]fileDiff {
+	switch groupBy {
+	case GroupByDirectory:
+		return groupFilesBy(files, func(p string) string { return path.Dir(p) })
+	case GroupByModel:
+		if available {
+			groups, err := groupFilesWithModel(client, files)
+			if err == nil {
+				return groups
+			}
+			fmt.Printf("Error grouping changes with ollama: %v\n", err)
+		}
+		fmt.Println("Falling back to grouping by package...")
+	}
+	return groupFilesBy(files, packageKey)
+}
+
+// groupFilesWithModel lets the model cluster the changes. Modified files with
+// several hunks are offered hunk by hunk, so unrelated edits to the same file
+// can end up in different commits.
+func groupFilesWithModel(client *ollama.Client, files []fileDiff) ([][]fileDiff, error) {
+	changes := splitChanges(files)
+	byID := make(map[string]splitChange, len(changes))
+	ids := make([]string, 0, len(changes))
+	var diff strings.Builder
+	for _, c := range changes {
+		byID[c.id] = c
+		ids = append(ids, c.id)
+		fmt.Fprintf(&diff, "Change %s:\n%s", c.id, c.diff(files))
+	}
+
+	ctx := context.Background()
+
+	idGroups, err := client.GroupChanges(ctx, ids, diff.String())
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([][]fileDiff, 0, len(idGroups))
+	for _, idGroup := range idGroups {
+		var order []int
+		selected := map[int][]bool{}
+		for _, id := range idGroup {
+			c := byID[id]
+			if _, ok := selected[c.file]; !ok {
+				order = append(order, c.file)
+				selected[c.file] = make([]bool, max(1, len(files[c.file].Hunks)))
+			}
+			if c.hunk < 0 {
+				for i := range selected[c.file] {
+					selected[c.file][i] = true
+				}
+			} else {
+				selected[c.file][c.hunk] = true
+			}
+		}
+
+		var group []fileDiff
+		for _, i := range order {
+			if sub, ok := selectHunks(files[i], selected[i]); ok {
+				group = append(group, sub)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// splitChange is a part of the staged changes that can be committed on its
+// own: one hunk of a modified file, or a whole file.
+type splitChange struct {
+	// id names the change for the model, "path" or "path#2" for the second hunk
+	id   string
+	file int
+	// hunk is the index into the file's hunks, -1 for the whole file
+	hunk int
+}
+
+func (c splitChange) diff(files []fileDiff) string {
+	f := files[c.file]
+	if c.hunk < 0 {
+		return f.String()
+	}
+	return fileDiff{Path: f.Path, Header: f.Header, Hunks: []hunk{f.Hunks[c.hunk]}}.String()
+}
+
+// splitChanges breaks files into hunks where the hunks can be applied
+// independently of each other.
+func splitChanges(files []fileDiff) []splitChange {
+	var changes []splitChange
+	for i, f := range files {
+		if len(f.Hunks) < 2 || !plainModification(f) {
+			changes = append(changes, splitChange{id: f.Path, file: i, hunk: -1})
+			continue
+		}
+		for j := range f.Hunks {
+			changes = append(changes, splitChange{id: fmt.Sprintf("%s#%d", f.Path, j+1), file: i, hunk: j})
+		}
+	}
+	return changes
+}
+
+// plainModification reports whether f only changes the content of an
+// existing file. Creations, deletions, renames and mode changes have to be
+// committed as a whole.
+func plainModification(f fileDiff) bool {
+	for _, line := range f.Header[1:] {
+		if !strings.HasPrefix(line, "index ") && !strings.HasPrefix(line, "--- ") && !strings.HasPrefix(line, "+++ ") {
+			return false
+		}
+	}
+	return true
+}
+
+// planPatch returns the part of the binary patch of a file that holds the
+// hunks of f, which may be a subset of the file's hunks. Text hunks are the
+// same in both patches, only the header differs.
+func planPatch(patch, f fileDiff) fileDiff {
+	if len(f.Hunks) == 0 || len(f.Hunks) == len(patch.Hunks) {
+		return patch
+	}
+	return fileDiff{Path: patch.Path, Header: patch.Header, Hunks: f.Hunks}
+}
+
+// groupFilesBy buckets files by key, keeping groups in order of first appearance.
+func groupFilesBy(files []fileDiff, key func(string) string) [][]fileDiff {
+	index := map[string]int{}
+	var groups [][]fileDiff
+	for _, f := range files {
+		k := key(f.Path)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], f)
+	}
+	return groups
+}
+
+// packageKey groups Go files by their package directory and every other
+// file by its top-level directory.
+func packageKey(p string) string {
+	if strings.HasSuffix(p, ".go") {
+		return path.Dir(p)
+	}
+	if idx := strings.Index(p, "/"); idx >= 0 {
+		return p[:idx]
+	}
+	return "."
+}
+
+// commitPlans unstages everything and then stages and commits each plan in
+// turn. Hunks of a file split across plans are applied at an offset, which
+// git apply locates through their context lines. A plan is only committed
+// when the index holds exactly its changes. On failure the changes of the
+// remaining plans are staged again.
+func commitPlans(plans []commitPlan, commitOpts CommitOptions) error {
+	if err := unstageAll(); err != nil {
+		return err
+	}
+
+	for i, plan := range plans {
+		err := applyToIndex(plan.patch)
+		if err == nil {
+			err = checkStaged(plan.patch)
+		}
+		if err == nil {
+			err = createCommit(plan.message, commitOpts)
+		}
+		if err != nil {
+			restorePlans(plans[i:])
+			return fmt.Errorf("commit %d/%d (%s): %w", i+1, len(plans), strings.Join(plan.files, ", "), err)
+		}
+		fmt.Printf("%s✅ Created commit %d/%d:%s %s\n", ColorGreen, i+1, len(plans), ColorReset, plan.message.Title)
+	}
+	return nil
+}
+
+func restorePlans(plans []commitPlan) {
+	if err := unstageAll(); err != nil {
+		logrus.WithError(err).Warn("could not reset index")
+		return
+	}
+	for _, plan := range plans {
+		if err := applyToIndex(plan.patch); err != nil {
+			logrus.WithError(err).Warnf("could not re-stage %s", strings.Join(plan.files, ", "))
+		}
+	}
+}
+
+func getStagedPatch() (string, error) {
+	cmd := exec.Command("git", "diff", "--staged", "--binary")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git diff: %w", err)
+	}
+	return string(output), nil
+}
+
+func unstageAll() error {
+	cmd := exec.Command("git", "reset", "-q")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git reset failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// applyToIndex stages patch. The paths in the patch are relative to the top
+// level, so it is applied there: from a subdirectory git apply skips the
+// paths outside of it.
+func applyToIndex(patch string) error {
+	root, err := topLevel()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "apply", "--cached", "-")
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(patch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git apply --cached failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// checkStaged returns an error unless the index holds exactly the changes of
+// patch.
+func checkStaged(patch string) error {
+	staged, err := getStagedPatch()
+	if err != nil {
+		return err
+	}
+
+	expected := changedLines(parseDiff(patch))
+	actual := changedLines(parseDiff(staged))
+	var mismatched []string
+	for _, p := range sortedKeys(expected) {
+		if lines, ok := actual[p]; !ok || !slices.Equal(lines, expected[p]) {
+			mismatched = append(mismatched, p)
+		}
+	}
+	for _, p := range sortedKeys(actual) {
+		if _, ok := expected[p]; !ok {
+			mismatched = append(mismatched, p)
+		}
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("the index does not match the planned changes of %s", strings.Join(mismatched, ", "))
+	}
+	return nil
+}
+
+// changedLines maps the path of every file to its added and removed lines.
+func changedLines(files []fileDiff) map[string][]string {
+	changes := map[string][]string{}
+	for _, f := range files {
+		lines := []string{}
+		for _, h := range f.Hunks {
+			for _, line := range h.Lines {
+				if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+					lines = append(lines, line)
+				}
+			}
+		}
+		changes[f.Path] = lines
+	}
+	return changes
+}
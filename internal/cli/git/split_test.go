@@ -0,0 +1,215 @@
+package git
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+func TestGroupFilesBy(t *testing.T) {
+	paths := []string{
+		"internal/api/server.go",
+		"README.md",
+		"internal/api/server_test.go",
+		"docs/guide/setup.md",
+		"internal/db/store.go",
+		"docs/index.md",
+		"Makefile",
+	}
+	var files []fileDiff
+	for _, p := range paths {
+		files = append(files, fileDiff{Path: p})
+	}
+
+	tests := []struct {
+		name     string
+		key      func(string) string
+		expected [][]string
+	}{
+		{
+			name: "By directory",
+			key:  path.Dir,
+			expected: [][]string{
+				{"internal/api/server.go", "internal/api/server_test.go"},
+				{"README.md", "Makefile"},
+				{"docs/guide/setup.md"},
+				{"internal/db/store.go"},
+				{"docs/index.md"},
+			},
+		},
+		{
+			name: "By package",
+			key:  packageKey,
+			expected: [][]string{
+				{"internal/api/server.go", "internal/api/server_test.go"},
+				{"README.md", "Makefile"},
+				{"docs/guide/setup.md", "docs/index.md"},
+				{"internal/db/store.go"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result [][]string
+			for _, group := range groupFilesBy(files, tt.key) {
+				var names []string
+				for _, f := range group {
+					names = append(names, f.Path)
+				}
+				result = append(result, names)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("groupFilesBy() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitChanges(t *testing.T) {
+	twoHunks := []hunk{{Header: "@@ -1 +1 @@"}, {Header: "@@ -20 +20 @@"}}
+	files := []fileDiff{
+		{Path: "main.go", Header: []string{"diff --git a/main.go b/main.go", "index 1234567..7890abc 100644", "--- a/main.go", "+++ b/main.go"}, Hunks: twoHunks},
+		{Path: "new.go", Header: []string{"diff --git a/new.go b/new.go", "new file mode 100644", "--- /dev/null", "+++ b/new.go"}, Hunks: twoHunks},
+		{Path: "one.go", Header: []string{"diff --git a/one.go b/one.go", "--- a/one.go", "+++ b/one.go"}, Hunks: twoHunks[:1]},
+	}
+
+	var ids []string
+	for _, c := range splitChanges(files) {
+		ids = append(ids, c.id)
+	}
+	expected := []string{"main.go#1", "main.go#2", "new.go", "one.go"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("splitChanges() = %q, want %q", ids, expected)
+	}
+}
+
+// newTestRepo creates a git repository in a temporary directory, changes
+// into it and returns a function running git commands in it.
+func newTestRepo(t *testing.T) func(args ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Chdir(t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	run := func(args ...string) string {
+		t.Helper()
+		output, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+		}
+		return string(output)
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.name", "Test")
+	run("config", "user.email", "test@example.com")
+	return run
+}
+
+func writeTestFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(path.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSplitCommitsSeparatesHunks(t *testing.T) {
+	git := newTestRepo(t)
+
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, "line")
+	}
+	writeTestFile(t, "notes.txt", strings.Join(lines, "\n")+"\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	lines[1] = "first change"
+	lines[27] = "second change"
+	writeTestFile(t, "notes.txt", strings.Join(lines, "\n")+"\n")
+	git("add", ".")
+
+	// The later hunk is committed first, so the earlier one applies at an offset
+	response := "GROUP: notes.txt#2\nGROUP: notes.txt#1\nTITLE: docs: update notes"
+	server := chainServer(t, []string{"llama3.2:latest"}, map[string]string{"llama3.2": response})
+	defer server.Close()
+
+	stdin = bufio.NewReader(strings.NewReader("y\n"))
+	defer func() { stdin = bufio.NewReader(os.Stdin) }()
+
+	SplitCommits(server.URL, "llama3.2", nil, "professional", "", GroupByModel, false, false, CommitOptions{})
+
+	if log := git("log", "--format=%s"); strings.Count(log, "docs: update notes") != 2 {
+		t.Fatalf("expected two split commits, got log:\n%s", log)
+	}
+	if diff := git("show", "HEAD~1", "--format="); !strings.Contains(diff, "+second change") || strings.Contains(diff, "+first change") {
+		t.Errorf("first split commit should only hold the second hunk:\n%s", diff)
+	}
+	if status := git("status", "--porcelain"); status != "" {
+		t.Errorf("expected a clean tree after splitting, got:\n%s", status)
+	}
+}
+
+func TestSplitCommitsFromSubdirectory(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "sub/s.txt", "s\n")
+	writeTestFile(t, "other/o.txt", "o\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	writeTestFile(t, "sub/s.txt", "s2\n")
+	writeTestFile(t, "other/o.txt", "o2\n")
+	git("add", ".")
+	t.Chdir("sub")
+
+	ollama.SetTimeouts(ollama.DefaultTimeouts(), ollama.RetryPolicy{})
+	defer ollama.SetTimeouts(ollama.DefaultTimeouts(), ollama.DefaultRetryPolicy())
+	stdin = bufio.NewReader(strings.NewReader("y\n"))
+	defer func() { stdin = bufio.NewReader(os.Stdin) }()
+
+	SplitCommits("http://127.0.0.1:1", "llama3.2", nil, "professional", "", GroupByDirectory, false, false, CommitOptions{})
+
+	if count := strings.TrimSpace(git("rev-list", "--count", "HEAD")); count != "3" {
+		t.Fatalf("expected two split commits on top of the initial one, got %s", count)
+	}
+	if status := git("status", "--porcelain"); status != "" {
+		t.Errorf("expected every change to be committed, got:\n%s", status)
+	}
+}
+
+func TestCheckStaged(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "a.txt", "a\n")
+	writeTestFile(t, "b.txt", "b\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	writeTestFile(t, "a.txt", "a2\n")
+	writeTestFile(t, "b.txt", "b2\n")
+	git("add", "a.txt")
+	staged := git("diff", "--staged", "--binary")
+	git("add", "b.txt")
+	both := git("diff", "--staged", "--binary")
+
+	if err := checkStaged(both); err != nil {
+		t.Errorf("checkStaged() unexpected error: %v", err)
+	}
+	if err := checkStaged(staged); err == nil || !strings.Contains(err.Error(), "b.txt") {
+		t.Errorf("checkStaged() = %v, want an error naming b.txt", err)
+	}
+}
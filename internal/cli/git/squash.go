@@ -0,0 +1,166 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// SquashCommits synthesizes one commit message for the commits on the
+// current branch since its merge-base with base, with Co-authored-by
+// trailers for every other author. The models of the chain are tried in
+// order. With commit it soft resets to the merge-base and commits the
+// squashed changes.
+func SquashCommits(ollamaURL, ollamaModel string, fallbackModels []string, tone, language, base string, commit bool, commitOpts CommitOptions) {
+	trailers, err := commitOpts.trailers()
+	if err != nil {
+		fmt.Printf("%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	base, mergeBase, err := findMergeBase(base)
+	if err != nil {
+		fmt.Printf("%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	commitRange := mergeBase + "..HEAD"
+	commits, err := readCommits(commitRange)
+	if err != nil {
+		fmt.Printf("%sError reading commits: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+	if len(commits) == 0 {
+		fmt.Printf("%sNo commits found between %s and HEAD.%s\n", ColorYellow, base, ColorReset)
+		return
+	}
+
+	commitLog, err := getCommitLog(commitRange)
+	if err != nil {
+		fmt.Printf("%sError reading commits: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	diff, err := runGitDiff("diff", mergeBase, "HEAD")
+	if err != nil {
+		fmt.Printf("%sError getting diff: %v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	fmt.Printf("Squashing %d commit(s) since %s\n", len(commits), base)
+
+	chain := newModelChain(ollamaURL, ollamaModel, fallbackModels)
+	ctx := context.Background()
+
+	commitMsg := fallbackSquashMessage(commits)
+	if chain.available(ctx) {
+		chain.run("squash message", func(client *ollama.Client) error {
+			generated, err := client.GenerateSquashMessage(ctx, commitLog, diff, tone, language)
+			if err == nil {
+				commitMsg = generated
+			}
+			return err
+		})
+	}
+
+	// The subjects of the squashed commits may already carry the ticket
+	ticketPrefix := currentTicketPrefix(os.Stdout)
+	commitMsg.Title = strings.TrimPrefix(commitMsg.Title, ticketPrefix)
+
+	if changes := detectBreakingChanges(diff); len(changes) > 0 {
+		commitMsg.Title = markBreakingTitle(commitMsg.Title)
+		commitMsg.Breaking = strings.Join(changes, "; ")
+	}
+	commitMsg.Title = ticketPrefix + commitMsg.Title
+	commitMsg.Trailers = coAuthorTrailers(commits, gitConfig("user.email"))
+	commitMsg.Trailers = mergeTrailers(commitMsg.Trailers, trailers)
+
+	printCommitMessage(commitMsg, os.Stdout)
+
+	if !commit {
+		return
+	}
+
+	// Staged changes would silently end up in the squashed commit
+	if exec.Command("git", "diff", "--staged", "--quiet").Run() != nil {
+		fmt.Printf("%sThere are staged changes, commit or unstage them before squashing.%s\n", ColorRed, ColorReset)
+		return
+	}
+
+	ok, err := confirm(fmt.Sprintf("\nDo you want to squash %d commit(s) into one with this message? (y/N): ", len(commits)), os.Stdout)
+	if err != nil {
+		fmt.Printf("Error reading input: %v\n", err)
+		return
+	}
+	if !ok {
+		fmt.Println("Commits not squashed.")
+		return
+	}
+
+	head, err := resolveRevision("HEAD")
+	if err != nil {
+		fmt.Printf("%s%v%s\n", ColorRed, err, ColorReset)
+		return
+	}
+
+	if output, err := exec.Command("git", "reset", "--soft", mergeBase).CombinedOutput(); err != nil {
+		fmt.Printf("%sgit reset --soft failed: %v\nOutput: %s%s\n", ColorRed, err, string(output), ColorReset)
+		return
+	}
+
+	if err := createCommit(commitMsg, commitOpts); err != nil {
+		fmt.Printf("%sError creating commit: %v%s\n", ColorRed, err, ColorReset)
+		// Put the branch back where it was
+		if output, err := exec.Command("git", "reset", "--soft", head).CombinedOutput(); err != nil {
+			fmt.Printf("%sCould not restore %s: %v\nOutput: %s%s\n", ColorRed, shortSHA(head), err, string(output), ColorReset)
+		}
+		return
+	}
+	fmt.Printf("%s✅ %d commit(s) squashed successfully!%s\n", ColorGreen, len(commits), ColorReset)
+}
+
+// fallbackSquashMessage lists the squashed commit subjects when the model
+// is unavailable.
+func fallbackSquashMessage(commits []commitInfo) ollama.CommitMessage {
+	if len(commits) == 1 {
+		return ollama.CommitMessage{Title: commits[0].Subject, Description: commits[0].Body}
+	}
+
+	lines := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		lines = append(lines, "- "+commit.Subject)
+	}
+	return ollama.CommitMessage{
+		Title:       commits[0].Subject,
+		Description: "Squashed commits:\n" + strings.Join(lines, "\n"),
+	}
+}
+
+// coAuthorTrailers returns a Co-authored-by trailer for every distinct
+// author except self, in order of first commit.
+func coAuthorTrailers(commits []commitInfo, self string) []string {
+	seen := map[string]bool{strings.ToLower(self): true}
+	var trailers []string
+	for _, commit := range commits {
+		email := strings.ToLower(commit.AuthorEmail)
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		trailers = append(trailers, fmt.Sprintf("Co-authored-by: %s <%s>", commit.AuthorName, commit.AuthorEmail))
+	}
+	return trailers
+}
+
+// gitConfig returns the value of a git config key, or an empty string.
+func gitConfig(key string) string {
+	output, err := exec.Command("git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+func TestCoAuthorTrailers(t *testing.T) {
+	commits := []commitInfo{
+		{AuthorName: "Me", AuthorEmail: "me@example.com"},
+		{AuthorName: "Ada", AuthorEmail: "ada@example.com"},
+		{AuthorName: "Grace", AuthorEmail: "grace@example.com"},
+		{AuthorName: "Ada L.", AuthorEmail: "ADA@example.com"},
+	}
+
+	expected := []string{
+		"Co-authored-by: Ada <ada@example.com>",
+		"Co-authored-by: Grace <grace@example.com>",
+	}
+
+	if result := coAuthorTrailers(commits, "Me@example.com"); !reflect.DeepEqual(result, expected) {
+		t.Errorf("coAuthorTrailers() = %q, want %q", result, expected)
+	}
+}
+
+func TestFallbackSquashMessage(t *testing.T) {
+	commits := []commitInfo{
+		{Subject: "Add login form"},
+		{Subject: "Fix typo"},
+	}
+
+	result := fallbackSquashMessage(commits)
+	if result.Title != "Add login form" {
+		t.Errorf("fallbackSquashMessage().Title = %q, want %q", result.Title, "Add login form")
+	}
+	expected := "Squashed commits:\n- Add login form\n- Fix typo"
+	if result.Description != expected {
+		t.Errorf("fallbackSquashMessage().Description = %q, want %q", result.Description, expected)
+	}
+}
+
+func TestSquashCommitsAddsTicketOnce(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "a.txt", "a\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("checkout", "-q", "-b", "feature/BP-1-export")
+	writeTestFile(t, "b.txt", "b\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "BP-1: add export")
+
+	ollama.SetTimeouts(ollama.DefaultTimeouts(), ollama.RetryPolicy{})
+	defer ollama.SetTimeouts(ollama.DefaultTimeouts(), ollama.DefaultRetryPolicy())
+
+	stdout, _ := captureOutput(t, func() {
+		SquashCommits("http://127.0.0.1:1", "llama3.2", nil, "professional", "", "main", false, CommitOptions{})
+	})
+
+	if !strings.Contains(stdout, "BP-1: add export") || strings.Contains(stdout, "BP-1: BP-1:") {
+		t.Errorf("squashed title should carry the ticket once, got:\n%s", stdout)
+	}
+}
+
+func TestSquashCommitsUsesFallbackModel(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "a.txt", "a\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("checkout", "-q", "-b", "export")
+	writeTestFile(t, "b.txt", "b\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "wip")
+
+	server := chainServer(t, []string{"llama3.2:3b"}, map[string]string{
+		"llama3.2:3b": "TITLE: feat: add export\nDESCRIPTION: Adds the export.",
+	})
+	defer server.Close()
+
+	stdout, _ := captureOutput(t, func() {
+		SquashCommits(server.URL, "qwen2.5-coder:7b", []string{"llama3.2:3b"}, "professional", "", "main", false, CommitOptions{})
+	})
+
+	if !strings.Contains(stdout, "feat: add export") {
+		t.Errorf("the fallback model should write the squash message, got:\n%s", stdout)
+	}
+}
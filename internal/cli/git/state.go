@@ -0,0 +1,281 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Operations git can be in the middle of when a commit is created
+const (
+	stateMerge      = "merge"
+	stateRevert     = "revert"
+	stateCherryPick = "cherry-pick"
+)
+
+var mergeBranchPattern = regexp.MustCompile(`^Merge (?:remote-tracking )?branch '([^']+)'`)
+
+// repoState describes an in-progress merge, revert or cherry-pick. Kind is
+// empty when the repository is in none of them.
+type repoState struct {
+	Kind string
+	// SHAs are the merged, reverted or cherry-picked commits
+	SHAs []string
+	// Branch is the merged branch
+	Branch string
+	// Subject is the subject of the reverted or cherry-picked commit
+	Subject string
+	// Conflicts are the files git reported as conflicted
+	Conflicts []string
+	// Resolutions is the combined diff of hunks that differ from every
+	// parent, i.e. how the conflicts were resolved. It is filled in by
+	// resolveConflicts once the resolved files are staged.
+	Resolutions string
+}
+
+// detectRepoState inspects .git for MERGE_HEAD, REVERT_HEAD and
+// CHERRY_PICK_HEAD.
+func detectRepoState() repoState {
+	if shas := readHeadFile("MERGE_HEAD"); len(shas) > 0 {
+		mergeMsg := readGitFile("MERGE_MSG")
+		return repoState{
+			Kind:      stateMerge,
+			SHAs:      shas,
+			Branch:    mergedBranchName(mergeMsg, shas[0]),
+			Conflicts: conflictedFiles(mergeMsg),
+		}
+	}
+
+	if shas := readHeadFile("REVERT_HEAD"); len(shas) > 0 {
+		return repoState{Kind: stateRevert, SHAs: shas, Subject: commitSubject(shas[0]), Conflicts: conflictedFiles(readGitFile("MERGE_MSG"))}
+	}
+
+	if shas := readHeadFile("CHERRY_PICK_HEAD"); len(shas) > 0 {
+		return repoState{Kind: stateCherryPick, SHAs: shas, Subject: commitSubject(shas[0]), Conflicts: conflictedFiles(readGitFile("MERGE_MSG"))}
+	}
+
+	return repoState{}
+}
+
+// promptContext explains the state to the model and how to write the message.
+func (s repoState) promptContext() string {
+	var b strings.Builder
+	switch s.Kind {
+	case stateMerge:
+		fmt.Fprintf(&b, "MERGE INSTRUCTION: This commit merges branch '%s'. Start the TITLE with \"Merge %s: \" followed by a summary of what the branch brings in.", s.Branch, s.Branch)
+		if len(s.Conflicts) > 0 {
+			fmt.Fprintf(&b, " The DESCRIPTION must summarize how the conflicts in %s were resolved.", strings.Join(s.Conflicts, ", "))
+		}
+		if s.Resolutions != "" {
+			fmt.Fprintf(&b, "\n\nConflict resolution hunks (combined diff against both parents):\n%s", s.Resolutions)
+		}
+	case stateRevert:
+		fmt.Fprintf(&b, "REVERT INSTRUCTION: This commit reverts commit %s (\"%s\"). Start the TITLE with \"Revert\" and explain in the DESCRIPTION what the reverted commit did and why undoing it is needed.", shortSHA(s.SHAs[0]), s.Subject)
+	case stateCherryPick:
+		fmt.Fprintf(&b, "CHERRY-PICK INSTRUCTION: This commit cherry-picks commit %s (\"%s\") onto the current branch. Keep the meaning of the original message.", shortSHA(s.SHAs[0]), s.Subject)
+	}
+	return b.String()
+}
+
+// apply enforces the parts of the message the state dictates, regardless of
+// whether the model followed the instructions.
+func (s repoState) apply(title, description string) (string, string, []string) {
+	var trailers []string
+	switch s.Kind {
+	case stateMerge:
+		prefix := "Merge " + s.Branch
+		if !strings.HasPrefix(title, "Merge") {
+			title = prefix + ": " + title
+		}
+		if len(s.Conflicts) > 0 && !mentionsAll(description, s.Conflicts) {
+			description = strings.TrimSpace(description + "\n\nResolved conflicts in: " + strings.Join(s.Conflicts, ", ") + ".")
+		}
+	case stateRevert:
+		if !strings.HasPrefix(title, "Revert") {
+			title = fmt.Sprintf("Revert \"%s\"", s.Subject)
+		}
+		reference := fmt.Sprintf("This reverts commit %s.", s.SHAs[0])
+		if !strings.Contains(description, s.SHAs[0]) {
+			description = strings.TrimSpace(description + "\n\n" + reference)
+		}
+	case stateCherryPick:
+		trailers = append(trailers, fmt.Sprintf("(cherry picked from commit %s)", s.SHAs[0]))
+	}
+	return title, description, trailers
+}
+
+// fallbackTitle is the rule-based title for the state, or empty when there
+// is no state.
+func (s repoState) fallbackTitle() string {
+	switch s.Kind {
+	case stateMerge:
+		return fmt.Sprintf("Merge branch '%s'", s.Branch)
+	case stateRevert:
+		return fmt.Sprintf("Revert \"%s\"", s.Subject)
+	case stateCherryPick:
+		return s.Subject
+	}
+	return ""
+}
+
+func mentionsAll(text string, words []string) bool {
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// unresolvedFiles lists unmerged paths that still need conflict resolution.
+// When the caller is about to stage everything, unmerged files without
+// conflict markers count as resolved. Files that cannot be read are kept, as
+// they cannot be checked.
+func unresolvedFiles(willStage bool) []string {
+	output, err := exec.Command("git", "diff", "--name-only", "--diff-filter=U", "-z").Output()
+	if err != nil {
+		return nil
+	}
+
+	var unmerged []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			unmerged = append(unmerged, file)
+		}
+	}
+	if !willStage || len(unmerged) == 0 {
+		return unmerged
+	}
+
+	// The paths are relative to the top level, not the current directory
+	root, err := topLevel()
+	if err != nil {
+		return unmerged
+	}
+
+	var unresolved []string
+	for _, file := range unmerged {
+		data, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil || hasConflictMarkers(string(data)) {
+			unresolved = append(unresolved, file)
+		}
+	}
+	return unresolved
+}
+
+func hasConflictMarkers(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "<<<<<<< ") || strings.HasPrefix(line, ">>>>>>> ") {
+			return true
+		}
+	}
+	return false
+}
+
+// mergedBranchName takes the branch from MERGE_MSG, falling back to a
+// symbolic name for the merged commit.
+func mergedBranchName(mergeMsg, sha string) string {
+	if matches := mergeBranchPattern.FindStringSubmatch(mergeMsg); matches != nil {
+		return matches[1]
+	}
+	output, err := exec.Command("git", "name-rev", "--name-only", "--exclude=tags/*", sha).Output()
+	if name := strings.TrimSpace(string(output)); err == nil && name != "" && name != "undefined" {
+		return name
+	}
+	return shortSHA(sha)
+}
+
+// conflictedFiles parses the "# Conflicts:" list git writes to MERGE_MSG.
+func conflictedFiles(mergeMsg string) []string {
+	var files []string
+	inConflicts := false
+	for _, line := range strings.Split(mergeMsg, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# Conflicts:"):
+			inConflicts = true
+		case inConflicts && strings.HasPrefix(line, "#\t"):
+			files = append(files, strings.TrimPrefix(line, "#\t"))
+		case inConflicts && line != "#":
+			inConflicts = false
+		}
+	}
+	return files
+}
+
+// resolveConflicts records how the conflicts of a merge were resolved. It
+// must run after staging, while the index still has unmerged entries the
+// resolution cannot be read.
+func (s *repoState) resolveConflicts() {
+	if s.Kind == stateMerge {
+		s.Resolutions = resolutionDiff(s.SHAs)
+	}
+}
+
+// resolutionDiff returns the combined diff of the index against HEAD and the
+// merged commits, which only contains hunks that differ from every parent.
+func resolutionDiff(mergeHeads []string) string {
+	// The tree is the one the merge commit will point to, so writing it
+	// leaves nothing behind
+	tree, err := exec.Command("git", "write-tree").Output()
+	if err != nil {
+		return ""
+	}
+
+	args := []string{"diff", "--cc", strings.TrimSpace(string(tree)), "HEAD"}
+	args = append(args, mergeHeads...)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func commitSubject(sha string) string {
+	output, err := exec.Command("git", "log", "-1", "--format=%s", sha).Output()
+	if err != nil {
+		return shortSHA(sha)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// readHeadFile returns the commit ids listed in a file such as MERGE_HEAD.
+func readHeadFile(name string) []string {
+	return strings.Fields(readGitFile(name))
+}
+
+// readGitFile reads a file from the git directory, returning an empty
+// string when it does not exist.
+func readGitFile(name string) string {
+	path, err := gitPath(name)
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// gitPath resolves name inside the git directory, taking worktrees into
+// account.
+func gitPath(name string) (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--git-path", name).Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// topLevel returns the root of the working tree, which the paths in diffs
+// are relative to.
+func topLevel() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,186 @@
+package git
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+func TestConflictedFiles(t *testing.T) {
+	mergeMsg := "Merge branch 'feature/x'\n\n# Conflicts:\n#\tinternal/api/server.go\n#\tREADME.md\n#\n# It looks like you may be committing a merge.\n"
+
+	expected := []string{"internal/api/server.go", "README.md"}
+	if result := conflictedFiles(mergeMsg); !reflect.DeepEqual(result, expected) {
+		t.Errorf("conflictedFiles() = %q, want %q", result, expected)
+	}
+
+	if result := conflictedFiles("Merge branch 'feature/x'\n"); result != nil {
+		t.Errorf("conflictedFiles() without conflicts = %q, want nil", result)
+	}
+}
+
+func TestMergedBranchName(t *testing.T) {
+	tests := []struct {
+		mergeMsg string
+		expected string
+	}{
+		{"Merge branch 'feature/x'", "feature/x"},
+		{"Merge branch 'main' of github.com:org/repo", "main"},
+		{"Merge remote-tracking branch 'origin/release'", "origin/release"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mergeMsg, func(t *testing.T) {
+			if result := mergedBranchName(tt.mergeMsg, "0123456789abcdef"); result != tt.expected {
+				t.Errorf("mergedBranchName() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRepoStateApply(t *testing.T) {
+	tests := []struct {
+		name                string
+		state               repoState
+		title               string
+		description         string
+		expectedTitle       string
+		expectedDescription string
+		expectedTrailers    []string
+	}{
+		{
+			name:                "Merge title prefix and conflict summary",
+			state:               repoState{Kind: stateMerge, Branch: "feature/x", Conflicts: []string{"api.go"}},
+			title:               "Add rate limiting",
+			description:         "Brings in the limiter.",
+			expectedTitle:       "Merge feature/x: Add rate limiting",
+			expectedDescription: "Brings in the limiter.\n\nResolved conflicts in: api.go.",
+		},
+		{
+			name:                "Merge title kept when model followed instructions",
+			state:               repoState{Kind: stateMerge, Branch: "feature/x", Conflicts: []string{"api.go"}},
+			title:               "Merge feature/x: Add rate limiting",
+			description:         "Kept both handlers in api.go.",
+			expectedTitle:       "Merge feature/x: Add rate limiting",
+			expectedDescription: "Kept both handlers in api.go.",
+		},
+		{
+			name:                "Revert references the reverted commit",
+			state:               repoState{Kind: stateRevert, SHAs: []string{"abc123def"}, Subject: "Add cache"},
+			title:               "Remove cache",
+			description:         "The cache served stale data.",
+			expectedTitle:       "Revert \"Add cache\"",
+			expectedDescription: "The cache served stale data.\n\nThis reverts commit abc123def.",
+		},
+		{
+			name:                "Cherry-pick adds trailer",
+			state:               repoState{Kind: stateCherryPick, SHAs: []string{"abc123def"}, Subject: "Fix crash"},
+			title:               "Fix crash",
+			description:         "Backports the fix.",
+			expectedTitle:       "Fix crash",
+			expectedDescription: "Backports the fix.",
+			expectedTrailers:    []string{"(cherry picked from commit abc123def)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, description, trailers := tt.state.apply(tt.title, tt.description)
+			if title != tt.expectedTitle {
+				t.Errorf("apply() title = %q, want %q", title, tt.expectedTitle)
+			}
+			if description != tt.expectedDescription {
+				t.Errorf("apply() description = %q, want %q", description, tt.expectedDescription)
+			}
+			if !reflect.DeepEqual(trailers, tt.expectedTrailers) {
+				t.Errorf("apply() trailers = %q, want %q", trailers, tt.expectedTrailers)
+			}
+		})
+	}
+}
+
+func TestMergeResolutionInPrompt(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "greeting.txt", "hello\nworld\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("checkout", "-q", "-b", "feature/loud")
+	writeTestFile(t, "greeting.txt", "HELLO\nworld\n")
+	git("commit", "-q", "-am", "shout")
+	git("checkout", "-q", "main")
+	writeTestFile(t, "greeting.txt", "hi\nworld\n")
+	git("commit", "-q", "-am", "shorten")
+	if err := exec.Command("git", "merge", "feature/loud").Run(); err == nil {
+		t.Fatal("expected the merge to conflict")
+	}
+	// Resolved in the working tree but not staged yet
+	writeTestFile(t, "greeting.txt", "HI\nworld\n")
+
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			json.NewEncoder(w).Encode(ollama.TagsResponse{Models: []ollama.ModelInfo{{Name: "llama3.2:latest"}}})
+		case "/api/generate":
+			var req ollama.GenerateRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			prompt = req.Prompt
+			json.NewEncoder(w).Encode(ollama.GenerateResponse{Response: "TITLE: Merge feature/loud: shout the short greeting", Done: true})
+		}
+	}))
+	defer server.Close()
+
+	captureOutput(t, func() {
+		GenerateCommitMessage(GenerateOptions{
+			OllamaURL:   server.URL,
+			OllamaModel: "llama3.2",
+			Tone:        "professional",
+			AutoStage:   true,
+			NoCache:     true,
+		})
+	})
+
+	if !strings.Contains(prompt, "Conflict resolution hunks") || !strings.Contains(prompt, "++HI") {
+		t.Errorf("prompt should contain the conflict resolution, got:\n%s", prompt)
+	}
+	if objects := git("cat-file", "--batch-all-objects", "--batch-check"); strings.Count(objects, " commit ") != 3 {
+		t.Errorf("generating the message should not create commits:\n%s", objects)
+	}
+}
+
+func TestUnresolvedFilesFromSubdirectory(t *testing.T) {
+	git := newTestRepo(t)
+
+	writeTestFile(t, "sub/f.txt", "hello\n")
+	writeTestFile(t, "sub/my notes.txt", "hello\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("checkout", "-q", "-b", "feature")
+	writeTestFile(t, "sub/f.txt", "HELLO\n")
+	writeTestFile(t, "sub/my notes.txt", "HELLO\n")
+	git("commit", "-q", "-am", "shout")
+	git("checkout", "-q", "main")
+	writeTestFile(t, "sub/f.txt", "hi\n")
+	writeTestFile(t, "sub/my notes.txt", "hi\n")
+	git("commit", "-q", "-am", "shorten")
+	if err := exec.Command("git", "merge", "feature").Run(); err == nil {
+		t.Fatal("expected the merge to conflict")
+	}
+	// Only the notes are resolved
+	writeTestFile(t, "sub/my notes.txt", "HI\n")
+	t.Chdir("sub")
+
+	if unresolved := unresolvedFiles(true); !reflect.DeepEqual(unresolved, []string{"sub/f.txt"}) {
+		t.Errorf("unresolvedFiles(true) = %q, want [sub/f.txt]", unresolved)
+	}
+	if unmerged := unresolvedFiles(false); !reflect.DeepEqual(unmerged, []string{"sub/f.txt", "sub/my notes.txt"}) {
+		t.Errorf("unresolvedFiles(false) = %q, want both conflicted files", unmerged)
+	}
+}
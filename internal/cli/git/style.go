@@ -0,0 +1,330 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// styleSampleSize is how many recent commits the style is learned from
+	styleSampleSize = 200
+	// styleRefreshCommits is how many new commits make the cached style stale
+	styleRefreshCommits = 50
+	// styleExampleCount is how many commit messages are used as examples
+	styleExampleCount = 3
+	// styleCacheFile is the cache location inside the git directory
+	styleCacheFile = "snippety/style.json"
+)
+
+var (
+	// Bots and automation that should not shape the learned style
+	botAuthorPattern = regexp.MustCompile(`(?i)(\[bot\]|\bbot@|-bot\b|dependabot|renovate|github-actions|greenkeeper|snyk)`)
+	// ":sparkles: Add ..." gitmoji shortcodes
+	emojiShortcodePattern = regexp.MustCompile(`^:[a-z0-9_+-]+:`)
+)
+
+// commitStyle summarizes the conventions of a repository's commit messages.
+type commitStyle struct {
+	// Head is the commit the style was learned at
+	Head string `json:"head"`
+	// Sampled is the number of human commits the style is based on
+	Sampled int `json:"sampled"`
+	// Conventional, Capitalized, Emoji, Period and WithBody are the share
+	// of sampled commits with that property, between 0 and 1
+	Conventional float64 `json:"conventional"`
+	Capitalized  float64 `json:"capitalized"`
+	Emoji        float64 `json:"emoji"`
+	Period       float64 `json:"period"`
+	WithBody     float64 `json:"with_body"`
+	// Types and Scopes are the most used Conventional Commits types and scopes
+	Types  []string `json:"types"`
+	Scopes []string `json:"scopes"`
+	// SubjectLength and BodyLines are medians over the sample
+	SubjectLength int `json:"subject_length"`
+	BodyLines     int `json:"body_lines"`
+	// Examples are representative full commit messages
+	Examples []string `json:"examples"`
+}
+
+// learnedCommitStyle returns the commit style of the current repository,
+// using the cached result unless HEAD has moved on by styleRefreshCommits
+// commits or more.
+func learnedCommitStyle() (commitStyle, error) {
+	head, err := resolveRevision("HEAD")
+	if err != nil {
+		return commitStyle{}, err
+	}
+
+	path, err := gitPath(styleCacheFile)
+	if err != nil {
+		return commitStyle{}, err
+	}
+
+	if cached, ok := readCachedStyle(path); ok && !styleIsStale(cached.Head, head) {
+		logrus.WithField("head", shortSHA(cached.Head)).Debug("using cached commit style")
+		return cached, nil
+	}
+
+	commits, err := readRecentCommits(styleSampleSize)
+	if err != nil {
+		return commitStyle{}, err
+	}
+	style := analyzeCommitStyle(humanCommits(commits))
+	style.Head = head
+
+	if err := writeCachedStyle(path, style); err != nil {
+		logrus.WithError(err).Warn("could not cache commit style")
+	}
+	return style, nil
+}
+
+func readCachedStyle(path string) (commitStyle, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return commitStyle{}, false
+	}
+	var style commitStyle
+	if err := json.Unmarshal(data, &style); err != nil {
+		logrus.WithError(err).Debug("ignoring unreadable commit style cache")
+		return commitStyle{}, false
+	}
+	return style, true
+}
+
+func writeCachedStyle(path string, style commitStyle) error {
+	data, err := json.MarshalIndent(style, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode commit style: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write commit style cache: %w", err)
+	}
+	return nil
+}
+
+// styleIsStale reports whether head has moved too far from the commit the
+// style was learned at, or away from it after a rewrite.
+func styleIsStale(learnedAt, head string) bool {
+	if learnedAt == head {
+		return false
+	}
+	output, err := exec.Command("git", "rev-list", "--count", learnedAt+".."+head).Output()
+	if err != nil {
+		return true
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	return err != nil || count >= styleRefreshCommits
+}
+
+// humanCommits drops commits authored by bots.
+func humanCommits(commits []commitInfo) []commitInfo {
+	var human []commitInfo
+	for _, commit := range commits {
+		if botAuthorPattern.MatchString(commit.AuthorName) || botAuthorPattern.MatchString(commit.AuthorEmail) {
+			continue
+		}
+		human = append(human, commit)
+	}
+	return human
+}
+
+// analyzeCommitStyle extracts the conventions of commits, newest first.
+func analyzeCommitStyle(commits []commitInfo) commitStyle {
+	style := commitStyle{Sampled: len(commits)}
+	if len(commits) == 0 {
+		return style
+	}
+
+	types := map[string]int{}
+	scopes := map[string]int{}
+	var subjectLengths, bodyLines []int
+	var conventional, capitalized, emoji, period, withBody int
+
+	for _, commit := range commits {
+		cc := parseConventionalCommit(commit.Subject, commit.Body)
+		if cc.Type != "" {
+			conventional++
+			types[cc.Type]++
+			if cc.Scope != "" {
+				scopes[cc.Scope]++
+			}
+		}
+
+		description := strings.TrimSpace(emojiShortcodePattern.ReplaceAllString(cc.Description, ""))
+		if hasEmoji(commit.Subject) {
+			emoji++
+		}
+		if first := firstLetter(description); unicode.IsUpper(first) {
+			capitalized++
+		}
+		if strings.HasSuffix(commit.Subject, ".") {
+			period++
+		}
+
+		subjectLengths = append(subjectLengths, len([]rune(commit.Subject)))
+		if commit.Body != "" {
+			withBody++
+			bodyLines = append(bodyLines, len(strings.Split(commit.Body, "\n")))
+		}
+	}
+
+	share := func(n int) float64 { return float64(n) / float64(len(commits)) }
+	style.Conventional = share(conventional)
+	style.Capitalized = share(capitalized)
+	style.Emoji = share(emoji)
+	style.Period = share(period)
+	style.WithBody = share(withBody)
+	style.Types = topKeys(types, 5)
+	style.Scopes = topKeys(scopes, 5)
+	style.SubjectLength = median(subjectLengths)
+	style.BodyLines = median(bodyLines)
+	style.Examples = styleExamples(commits, style.Conventional >= 0.5)
+	return style
+}
+
+// styleExamples picks recent messages that follow the dominant format,
+// preferring a different Conventional Commits type for each example.
+func styleExamples(commits []commitInfo, conventional bool) []string {
+	var examples []string
+	var skipped []commitInfo
+	seenTypes := map[string]bool{}
+
+	for _, commit := range commits {
+		if len(examples) == styleExampleCount {
+			break
+		}
+		cc := parseConventionalCommit(commit.Subject, commit.Body)
+		if (cc.Type != "") != conventional {
+			continue
+		}
+		if seenTypes[cc.Type] {
+			skipped = append(skipped, commit)
+			continue
+		}
+		seenTypes[cc.Type] = true
+		examples = append(examples, commitText(commit))
+	}
+
+	for _, commit := range skipped {
+		if len(examples) == styleExampleCount {
+			break
+		}
+		examples = append(examples, commitText(commit))
+	}
+	return examples
+}
+
+func commitText(commit commitInfo) string {
+	if commit.Body == "" {
+		return commit.Subject
+	}
+	return commit.Subject + "\n\n" + commit.Body
+}
+
+// instructions renders the style as prompt guidance. It is empty when too
+// few commits were sampled to tell.
+func (s commitStyle) instructions() string {
+	if s.Sampled < 5 {
+		return ""
+	}
+
+	var lines []string
+	switch {
+	case s.Conventional >= 0.6:
+		line := `- Use the Conventional Commits format "type(scope): description"`
+		if len(s.Types) > 0 {
+			line += ", usually with the types " + strings.Join(s.Types, ", ")
+		}
+		lines = append(lines, line)
+		if len(s.Scopes) > 0 {
+			lines = append(lines, "- Common scopes are "+strings.Join(s.Scopes, ", "))
+		}
+	case s.Conventional <= 0.2:
+		lines = append(lines, `- Do not use a "type:" prefix in the title`)
+	}
+
+	switch {
+	case s.Capitalized >= 0.7:
+		lines = append(lines, "- Start the title description with a capital letter")
+	case s.Capitalized <= 0.3:
+		lines = append(lines, "- Start the title description with a lowercase letter")
+	}
+
+	switch {
+	case s.Emoji >= 0.3:
+		lines = append(lines, "- Start the title with a fitting emoji")
+	case s.Emoji <= 0.05:
+		lines = append(lines, "- Do not use emojis")
+	}
+
+	if s.Period <= 0.1 {
+		lines = append(lines, "- Do not end the title with a period")
+	}
+	if s.SubjectLength > 0 {
+		lines = append(lines, fmt.Sprintf("- Titles are typically about %d characters long", s.SubjectLength))
+	}
+
+	switch {
+	case s.WithBody < 0.3:
+		lines = append(lines, "- Most commits have a one sentence description")
+	case s.BodyLines == 1:
+		lines = append(lines, "- Descriptions are typically a single line")
+	case s.BodyLines > 1:
+		lines = append(lines, fmt.Sprintf("- Descriptions are typically about %d lines long", s.BodyLines))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func hasEmoji(s string) bool {
+	if emojiShortcodePattern.MatchString(s) {
+		return true
+	}
+	for _, r := range s {
+		if r >= 0x1F300 && r <= 0x1FAFF || r >= 0x2600 && r <= 0x27BF {
+			return true
+		}
+	}
+	return false
+}
+
+func firstLetter(s string) rune {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return r
+		}
+	}
+	return 0
+}
+
+// topKeys returns up to n keys of counts, most frequent first.
+func topKeys(counts map[string]int, n int) []string {
+	keys := sortedKeys(counts)
+	sort.SliceStable(keys, func(i, j int) bool { return counts[keys[i]] > counts[keys[j]] })
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+func median(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	return sorted[len(sorted)/2]
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHumanCommits(t *testing.T) {
+	commits := []commitInfo{
+		{Subject: "feat: add login", AuthorName: "Ada", AuthorEmail: "ada@example.com"},
+		{Subject: "chore(deps): bump x", AuthorName: "dependabot[bot]", AuthorEmail: "49699333+dependabot[bot]@users.noreply.github.com"},
+		{Subject: "chore: update lockfile", AuthorName: "Renovate Bot", AuthorEmail: "bot@renovateapp.com"},
+		{Subject: "fix: handle nil", AuthorName: "Alan", AuthorEmail: "alan@example.com"},
+	}
+
+	result := humanCommits(commits)
+	if len(result) != 2 || result[0].AuthorName != "Ada" || result[1].AuthorName != "Alan" {
+		t.Errorf("humanCommits() kept %v, want the commits by Ada and Alan", result)
+	}
+}
+
+func TestAnalyzeCommitStyle(t *testing.T) {
+	commits := []commitInfo{
+		{Subject: "feat(api): add pagination", Body: "Adds cursor based pagination.\n\nCloses #12."},
+		{Subject: "fix(api): handle empty pages"},
+		{Subject: "feat(cli): add --json flag"},
+		{Subject: "docs: describe pagination"},
+		{Subject: "AUTH-1: fix(auth): refresh tokens"},
+		{Subject: "Update README"},
+	}
+
+	style := analyzeCommitStyle(commits)
+
+	if style.Sampled != 6 {
+		t.Errorf("Sampled = %d, want 6", style.Sampled)
+	}
+	if style.Conventional < 0.8 || style.Conventional > 0.85 {
+		t.Errorf("Conventional = %v, want 5/6", style.Conventional)
+	}
+	if style.Capitalized > 0.2 {
+		t.Errorf("Capitalized = %v, want 1/6", style.Capitalized)
+	}
+	if !reflect.DeepEqual(style.Types, []string{"feat", "fix", "docs"}) {
+		t.Errorf("Types = %q, want [feat fix docs]", style.Types)
+	}
+	if !reflect.DeepEqual(style.Scopes, []string{"api", "auth", "cli"}) {
+		t.Errorf("Scopes = %q, want [api auth cli]", style.Scopes)
+	}
+
+	expectedExamples := []string{
+		"feat(api): add pagination\n\nAdds cursor based pagination.\n\nCloses #12.",
+		"fix(api): handle empty pages",
+		"docs: describe pagination",
+	}
+	if !reflect.DeepEqual(style.Examples, expectedExamples) {
+		t.Errorf("Examples = %q, want %q", style.Examples, expectedExamples)
+	}
+}
+
+func TestCommitStyleInstructions(t *testing.T) {
+	style := commitStyle{
+		Sampled:       40,
+		Conventional:  0.9,
+		Capitalized:   0.1,
+		Emoji:         0,
+		Period:        0,
+		WithBody:      0.1,
+		Types:         []string{"feat", "fix"},
+		Scopes:        []string{"api"},
+		SubjectLength: 42,
+	}
+
+	instructions := style.instructions()
+	for _, expected := range []string{
+		"Conventional Commits format",
+		"types feat, fix",
+		"scopes are api",
+		"lowercase letter",
+		"Do not use emojis",
+		"Do not end the title with a period",
+		"about 42 characters",
+		"one sentence description",
+	} {
+		if !strings.Contains(instructions, expected) {
+			t.Errorf("instructions() = %q, missing %q", instructions, expected)
+		}
+	}
+
+	if (commitStyle{Sampled: 2, Conventional: 1}).instructions() != "" {
+		t.Error("instructions() should be empty for a tiny sample")
+	}
+}
+
+func TestHasEmoji(t *testing.T) {
+	tests := map[string]bool{
+		"✨ Add login":           true,
+		":bug: Fix crash":       true,
+		"🚀 Release 1.0":         true,
+		"Add login":             false,
+		"fix: handle :port: ok": false,
+	}
+	for subject, expected := range tests {
+		if result := hasEmoji(subject); result != expected {
+			t.Errorf("hasEmoji(%q) = %v, want %v", subject, result, expected)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// identityPattern matches a "Name <email>" identity as used in trailers.
+var identityPattern = regexp.MustCompile(`^[^<>]+ <[^<>\s]+@[^<>\s]+>$`)
+
+// CommitOptions controls the trailers, signing and body wrapping of the
+// commits snippety creates.
+type CommitOptions struct {
+	// Signoff adds a Signed-off-by trailer for the configured git user
+	Signoff bool
+	// CoAuthors are "Name <email>" identities added as Co-authored-by trailers
+	CoAuthors []string
+	// Sign signs commits even when commit.gpgsign is not set
+	Sign bool
+	// SigningKey selects the key to sign with, user.signingkey by default
+	SigningKey string
+	// Wrap is the width the message body is wrapped at, zero disables wrapping
+	Wrap int
+}
+
+// trailers returns the Co-authored-by trailers followed by the sign-off, which
+// by convention comes last.
+func (o CommitOptions) trailers() ([]string, error) {
+	var trailers []string
+	for _, author := range o.CoAuthors {
+		author = strings.TrimSpace(author)
+		if !identityPattern.MatchString(author) {
+			return nil, fmt.Errorf("invalid co-author %q, expected \"Name <email>\"", author)
+		}
+		trailers = append(trailers, "Co-authored-by: "+author)
+	}
+
+	if o.Signoff {
+		name, email := gitConfig("user.name"), gitConfig("user.email")
+		if name == "" || email == "" {
+			return nil, fmt.Errorf("cannot sign off: user.name and user.email must be set in git config")
+		}
+		trailers = append(trailers, fmt.Sprintf("Signed-off-by: %s <%s>", name, email))
+	}
+	return trailers, nil
+}
+
+// mergeTrailers appends extra to trailers, dropping case-insensitive duplicates.
+func mergeTrailers(trailers, extra []string) []string {
+	seen := map[string]bool{}
+	for _, trailer := range trailers {
+		seen[strings.ToLower(trailer)] = true
+	}
+	for _, trailer := range extra {
+		if seen[strings.ToLower(trailer)] {
+			continue
+		}
+		seen[strings.ToLower(trailer)] = true
+		trailers = append(trailers, trailer)
+	}
+	return trailers
+}
+
+// selectPairingPartners lets the user pick who they are pairing with from
+// the configured partners.
+func selectPairingPartners(partners []string, w io.Writer) ([]string, error) {
+	fmt.Fprintf(w, "\n%sPairing partners:%s\n", ColorBold+ColorBlue, ColorReset)
+	for i, partner := range partners {
+		fmt.Fprintf(w, "  %s%d%s %s\n", ColorBold, i+1, ColorReset, partner)
+	}
+
+	for {
+		fmt.Fprint(w, "\nWho are you pairing with? (e.g. \"1 3\", Enter for nobody): ")
+		response, err := stdin.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("error reading input: %w", err)
+		}
+
+		selected, err := parsePartnerSelection(response, partners)
+		if err != nil {
+			fmt.Fprintf(w, "%s%v%s\n", ColorRed, err, ColorReset)
+			continue
+		}
+		return selected, nil
+	}
+}
+
+// parsePartnerSelection maps the 1-based numbers in input to partners.
+func parsePartnerSelection(input string, partners []string) ([]string, error) {
+	var selected []string
+	for _, token := range strings.Fields(strings.ReplaceAll(input, ",", " ")) {
+		idx, err := strconv.Atoi(token)
+		if err != nil || idx < 1 || idx > len(partners) {
+			return nil, fmt.Errorf("invalid selection %q", token)
+		}
+		selected = append(selected, partners[idx-1])
+	}
+	return selected, nil
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommitOptionsTrailers(t *testing.T) {
+	opts := CommitOptions{CoAuthors: []string{"Ada Lovelace <ada@example.com>", " Alan Turing <alan@example.com> "}}
+	trailers, err := opts.trailers()
+	if err != nil {
+		t.Fatalf("trailers() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Co-authored-by: Ada Lovelace <ada@example.com>",
+		"Co-authored-by: Alan Turing <alan@example.com>",
+	}
+	if !reflect.DeepEqual(trailers, expected) {
+		t.Errorf("trailers() = %q, want %q", trailers, expected)
+	}
+}
+
+func TestCommitOptionsTrailersInvalidCoAuthor(t *testing.T) {
+	for _, author := range []string{"Ada Lovelace", "<ada@example.com>", "Ada <not an email>"} {
+		opts := CommitOptions{CoAuthors: []string{author}}
+		if _, err := opts.trailers(); err == nil {
+			t.Errorf("trailers() expected an error for co-author %q", author)
+		}
+	}
+}
+
+func TestMergeTrailersSkipsDuplicates(t *testing.T) {
+	existing := []string{"Co-authored-by: Ada Lovelace <ada@example.com>"}
+	extra := []string{"Co-authored-by: ada lovelace <ADA@example.com>", "Co-authored-by: Alan Turing <alan@example.com>"}
+
+	result := mergeTrailers(existing, extra)
+
+	expected := []string{
+		"Co-authored-by: Ada Lovelace <ada@example.com>",
+		"Co-authored-by: Alan Turing <alan@example.com>",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Trailers = %q, want %q", result, expected)
+	}
+}
+
+func TestParsePartnerSelection(t *testing.T) {
+	partners := []string{"Ada <ada@example.com>", "Alan <alan@example.com>", "Grace <grace@example.com>"}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "Nobody", input: "\n"},
+		{name: "Several", input: "1 3\n", expected: []string{partners[0], partners[2]}},
+		{name: "Comma separated", input: "2,3", expected: []string{partners[1], partners[2]}},
+		{name: "Out of range", input: "4", wantErr: true},
+		{name: "Not a number", input: "ada", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parsePartnerSelection(tt.input, partners)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePartnerSelection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parsePartnerSelection() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
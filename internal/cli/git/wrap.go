@@ -0,0 +1,143 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tahcohcat/snippety/internal/client/ollama"
+)
+
+// DefaultWrapWidth is the body width git's own tooling and most style
+// guides assume.
+const DefaultWrapWidth = 72
+
+// "- item", "* item", "+ item", "1. item", "2) item"
+var listItemPattern = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+`)
+
+// wrapMessage wraps the description of msg at width. Titles, footers and
+// trailers are left alone. A width of zero disables wrapping.
+func wrapMessage(msg ollama.CommitMessage, width int) ollama.CommitMessage {
+	msg.Description = wrapText(msg.Description, width)
+	return msg
+}
+
+// wrapText reflows the prose paragraphs and list items of text to width.
+// Fenced and indented code blocks, quotes and tables are kept as is, and
+// words are never split, so code spans and URLs stay intact even when they
+// are longer than width.
+func wrapText(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	var out []string
+	var block *wrapBlock
+	flush := func() {
+		if block != nil {
+			out = append(out, block.wrap(width)...)
+			block = nil
+		}
+	}
+
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			out = append(out, line)
+			inFence = !inFence
+		case inFence:
+			out = append(out, line)
+		case trimmed == "":
+			flush()
+			out = append(out, "")
+		case listItemPattern.MatchString(line):
+			flush()
+			marker := listItemPattern.FindString(line)
+			block = &wrapBlock{
+				prefix: marker,
+				indent: strings.Repeat(" ", utf8.RuneCountInString(marker)),
+				words:  splitWords(line[len(marker):]),
+			}
+		case block != nil:
+			// Continuation of the current paragraph or list item
+			block.words = append(block.words, splitWords(trimmed)...)
+		case strings.HasPrefix(line, "    "), strings.HasPrefix(line, "\t"),
+			strings.HasPrefix(trimmed, ">"), strings.HasPrefix(trimmed, "|"):
+			out = append(out, line)
+		default:
+			block = &wrapBlock{words: splitWords(trimmed)}
+		}
+	}
+	flush()
+
+	return strings.Join(out, "\n")
+}
+
+// wrapBlock is a paragraph or list item being reflowed. The first line
+// starts with prefix, the following ones with indent.
+type wrapBlock struct {
+	prefix string
+	indent string
+	words  []string
+}
+
+func (b *wrapBlock) wrap(width int) []string {
+	var lines []string
+	line := b.prefix
+	lineWords := 0
+	for _, word := range b.words {
+		if lineWords > 0 && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line = b.indent
+			lineWords = 0
+		}
+		if lineWords > 0 {
+			line += " "
+		}
+		line += word
+		lineWords++
+	}
+	return append(lines, line)
+}
+
+// splitWords splits text on whitespace, keeping `code spans` that contain
+// spaces together as one word.
+func splitWords(text string) []string {
+	var words []string
+	var span []string
+	for _, field := range strings.Fields(text) {
+		if span != nil {
+			span = append(span, field)
+			if strings.Count(field, "`")%2 == 1 {
+				words = append(words, strings.Join(span, " "))
+				span = nil
+			}
+			continue
+		}
+		if strings.Count(field, "`")%2 == 1 {
+			span = []string{field}
+			continue
+		}
+		words = append(words, field)
+	}
+	if span != nil {
+		// Unterminated span, keep the words as they are
+		words = append(words, span...)
+	}
+	return words
+}
+
+// truncateText cuts text to at most limit bytes without splitting a rune.
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
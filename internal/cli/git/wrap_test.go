@@ -0,0 +1,96 @@
+package git
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		width    int
+		expected string
+	}{
+		{
+			name:     "Short text is unchanged",
+			text:     "Adds a login form.",
+			width:    72,
+			expected: "Adds a login form.",
+		},
+		{
+			name:     "Long paragraph is reflowed",
+			text:     "Retries failed requests to the model server with exponential backoff and\ngives up after the configured timeout.",
+			width:    40,
+			expected: "Retries failed requests to the model\nserver with exponential backoff and\ngives up after the configured timeout.",
+		},
+		{
+			name:     "List items get a hanging indent",
+			text:     "Changes:\n\n- Back off exponentially between attempts to the server\n- Stop retrying\n  after the timeout",
+			width:    30,
+			expected: "Changes:\n\n- Back off exponentially\n  between attempts to the\n  server\n- Stop retrying after the\n  timeout",
+		},
+		{
+			name:     "Numbered list",
+			text:     "1. Parse the configuration file before anything else",
+			width:    30,
+			expected: "1. Parse the configuration\n   file before anything else",
+		},
+		{
+			name:     "Code spans and URLs are not broken",
+			text:     "Call `client.Generate(ctx, prompt)` as documented at https://github.com/ollama/ollama/blob/main/docs/api.md today",
+			width:    30,
+			expected: "Call\n`client.Generate(ctx, prompt)`\nas documented at\nhttps://github.com/ollama/ollama/blob/main/docs/api.md\ntoday",
+		},
+		{
+			name:     "Code blocks are kept",
+			text:     "Example:\n\n```\nsnippety --wrap 72 --interactive --signoff --co-author \"Ada <ada@example.com>\"\n```\n\n    go test ./... -run TestWrapText -count=1 -v -timeout 30s",
+			width:    30,
+			expected: "Example:\n\n```\nsnippety --wrap 72 --interactive --signoff --co-author \"Ada <ada@example.com>\"\n```\n\n    go test ./... -run TestWrapText -count=1 -v -timeout 30s",
+		},
+		{
+			name:     "Zero width disables wrapping",
+			text:     strings.Repeat("word ", 40),
+			width:    0,
+			expected: strings.Repeat("word ", 40),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := wrapText(tt.text, tt.width)
+			if result != tt.expected {
+				t.Errorf("wrapText() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	result := splitWords("use `git commit -F` instead of `-m`")
+	expected := []string{"use", "`git commit -F`", "instead", "of", "`-m`"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("splitWords() = %q, want %q", result, expected)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text     string
+		limit    int
+		expected string
+	}{
+		{"short", 10, "short"},
+		{"abcdef", 3, "abc"},
+		// "ä" is two bytes, cutting after its first byte drops it
+		{"aäb", 2, "a"},
+		{"aäb", 3, "aä"},
+		{"✨", 2, ""},
+	}
+	for _, tt := range tests {
+		if result := truncateText(tt.text, tt.limit); result != tt.expected {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.text, tt.limit, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BranchSuggestion is the change type and short slug for a branch name.
+type BranchSuggestion struct {
+	Type string
+	Slug string
+}
+
+// SuggestBranch proposes a change type and slug for a branch from a task
+// description or a git diff.
+func (c *Client) SuggestBranch(ctx context.Context, input string) (BranchSuggestion, error) {
+	prompt := fmt.Sprintf(`Based on the task description or git diff below, suggest a git branch name.
+
+Respond with exactly this format:
+TYPE: [one of feat, fix, docs, refactor, test, chore]
+SLUG: [2-5 lowercase words joined by hyphens]
+
+Slug requirements:
+- Describe what the work does, e.g. add-login-form
+- Only lowercase letters, digits and hyphens
+- Do not include ticket numbers
+
+Input:
+%s`, input)
+
+	response, err := c.Generate(ctx, prompt)
+	if err != nil {
+		return BranchSuggestion{}, err
+	}
+
+	suggestion := parseBranchSuggestion(response)
+	if suggestion.Slug == "" {
+		return BranchSuggestion{}, fmt.Errorf("model response did not contain a slug")
+	}
+	return suggestion, nil
+}
+
+func parseBranchSuggestion(response string) BranchSuggestion {
+	var suggestion BranchSuggestion
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "TYPE:") {
+			suggestion.Type = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "TYPE:")))
+		} else if strings.HasPrefix(line, "SLUG:") {
+			suggestion.Slug = strings.TrimSpace(strings.TrimPrefix(line, "SLUG:"))
+		}
+	}
+	return suggestion
+}
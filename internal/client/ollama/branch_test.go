@@ -0,0 +1,37 @@
+package ollama
+
+import (
+	"testing"
+)
+
+func TestParseBranchSuggestion(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected BranchSuggestion
+	}{
+		{
+			name:     "Well formed",
+			response: "TYPE: feat\nSLUG: add-login-form",
+			expected: BranchSuggestion{Type: "feat", Slug: "add-login-form"},
+		},
+		{
+			name:     "Extra text and casing",
+			response: "Sure!\n  TYPE: Fix  \n  SLUG: handle-nil-user\nGood luck.",
+			expected: BranchSuggestion{Type: "fix", Slug: "handle-nil-user"},
+		},
+		{
+			name:     "Missing format",
+			response: "feature/login",
+			expected: BranchSuggestion{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := parseBranchSuggestion(tt.response); result != tt.expected {
+				t.Errorf("parseBranchSuggestion() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var numberedLinePattern = regexp.MustCompile(`^(\d+)[.):]\s+(.+)$`)
+
+// RewriteChangelogEntries rewrites terse commit titles into user-facing
+// changelog bullet points. details holds supporting context for each entry,
+// such as the commit body and diff. Entries the model skips keep
+// their original text.
+func (c *Client) RewriteChangelogEntries(ctx context.Context, entries, details []string) ([]string, error) {
+	var list strings.Builder
+	for i, entry := range entries {
+		fmt.Fprintf(&list, "%d. %s\n", i+1, entry)
+		if i < len(details) && strings.TrimSpace(details[i]) != "" {
+			for _, line := range strings.Split(strings.TrimSpace(details[i]), "\n") {
+				fmt.Fprintf(&list, "   > %s\n", line)
+			}
+		}
+	}
+
+	prompt := fmt.Sprintf(`Rewrite the numbered commit titles below into changelog entries for end users.
+
+Respond with exactly one line per entry in this format:
+[number]. [changelog entry]
+
+Requirements:
+- Describe the user-visible effect, not the implementation
+- One short sentence per entry, starting with a capital letter
+- Do not add entries, do not merge entries, keep the numbering
+- Lines starting with '>' are context for the entry above and must not be copied
+
+Commits:
+%s`, list.String())
+
+	response, err := c.Generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNumberedLines(response, entries), nil
+}
+
+// parseNumberedLines maps "N. text" lines of response onto a copy of
+// fallback, leaving entries without a matching line unchanged.
+func parseNumberedLines(response string, fallback []string) []string {
+	result := make([]string, len(fallback))
+	copy(result, fallback)
+
+	for _, line := range strings.Split(response, "\n") {
+		matches := numberedLinePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		n, err := strconv.Atoi(matches[1])
+		if err != nil || n < 1 || n > len(result) {
+			continue
+		}
+		result[n-1] = strings.TrimSpace(matches[2])
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package ollama
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNumberedLines(t *testing.T) {
+	fallback := []string{"add login", "fix nil deref", "bump deps"}
+
+	tests := []struct {
+		name     string
+		response string
+		expected []string
+	}{
+		{
+			name: "All entries rewritten",
+			response: `1. Users can now log in with email
+2. Fixed a crash when opening an empty project
+3. Updated dependencies`,
+			expected: []string{"Users can now log in with email", "Fixed a crash when opening an empty project", "Updated dependencies"},
+		},
+		{
+			name: "Missing and out of range entries keep the original",
+			response: `Here you go:
+2) Fixed a crash when opening an empty project
+7. Something invented`,
+			expected: []string{"add login", "Fixed a crash when opening an empty project", "bump deps"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseNumberedLines(tt.response, fallback)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseNumberedLines() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
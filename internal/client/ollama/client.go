@@ -1,13 +1,14 @@
 package ollama
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"regexp"
 	"strings"
-	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -15,23 +16,41 @@ import (
 type Client struct {
 	BaseURL string
 	Model   string
-	client  *http.Client
+	// Options are sent with generate requests and KeepAlive with every
+	// request to the model
+	Options   Options
+	KeepAlive KeepAlive
+	// Retry controls retrying transient failures
+	Retry  RetryPolicy
+	client *http.Client
+	// timeouts holds the total timeout, which do enforces, and the connect
+	// timeout that bounds health checks
+	timeouts Timeouts
 }
 
 type GenerateRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	Model     string    `json:"model"`
+	Prompt    string    `json:"prompt"`
+	Stream    bool      `json:"stream"`
+	Options   *Options  `json:"options,omitempty"`
+	KeepAlive KeepAlive `json:"keep_alive,omitempty"`
 }
 
 type GenerateResponse struct {
 	Response string `json:"response"`
 	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
 }
 
 type CommitMessage struct {
 	Title       string
 	Description string
+	// Breaking describes backwards-incompatible changes and is rendered as a
+	// BREAKING CHANGE footer. It is empty when the change is compatible.
+	Breaking string
+	// Trailers are "Token: value" lines such as Co-authored-by, rendered
+	// after the BREAKING CHANGE footer.
+	Trailers []string
 }
 
 func NewClient(baseURL, model string) *Client {
@@ -43,67 +62,93 @@ func NewClient(baseURL, model string) *Client {
 	}
 
 	return &Client{
-		BaseURL: baseURL,
-		Model:   model,
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		BaseURL:   baseURL,
+		Model:     model,
+		Options:   defaultOptions,
+		KeepAlive: defaultKeepAlive,
+		Retry:     defaultRetryPolicy,
+		client:    newHTTPClient(defaultTimeouts),
+		timeouts:  defaultTimeouts,
 	}
 }
 
+// HealthCheck verifies that the server answers and that the client's model
+// is installed, returning a ModelNotFoundError when it is not. The check is
+// made once and only waits as long as connecting may take, so that commands
+// fall back to offline analysis right away when Ollama is down.
 func (c *Client) HealthCheck(ctx context.Context) error {
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/api/tags", nil)
+	probe := *c
+	probe.Retry = RetryPolicy{}
+	probe.timeouts.Total = c.timeouts.Connect
+	installed, err := probe.ListModels(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create health check request: %w", err)
+		return err
 	}
-
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("failed to connect to Ollama at %s: %w", c.BaseURL, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Ollama health check failed with status: %d", resp.StatusCode)
+	if !modelInstalled(c.Model, installed) {
+		return &ModelNotFoundError{Model: c.Model, Installed: installed}
 	}
-
 	return nil
 }
 
-func (c *Client) GenerateCommitMessage(ctx context.Context, diff string, tone string) (CommitMessage, error) {
-	toneInstruction := getToneInstruction(tone)
-
-	prompt := fmt.Sprintf(`Based on the git diff below, generate a commit message with both a title and description.
-
-%s
+// PromptContext carries optional information about the change that is added
+// to the commit message prompt.
+type PromptContext struct {
+	// State describes an in-progress merge, revert or cherry-pick and how
+	// the message for it should be written
+	State string
+	// Style lists the commit message conventions of the repository
+	Style string
+	// Examples are past commit messages to imitate
+	Examples []string
+	// Branch is the checked out branch and Ticket the ticket derived from it
+	Branch string
+	Ticket string
+	// Files are the paths changed by the diff and Stats a diffstat summary
+	Files []string
+	Stats string
+	// Language is the language tag the message is written in, e.g. "de",
+	// empty for English
+	Language string
+}
 
-Respond with exactly this format:
-TITLE: [short commit title]
-DESCRIPTION: [detailed description]
+func (c *Client) GenerateCommitMessage(ctx context.Context, diff string, tone string, pc PromptContext) (CommitMessage, error) {
+	prompt, err := RenderCommitPrompt(diff, tone, pc)
+	if err != nil {
+		return CommitMessage{}, err
+	}
 
-Title requirements:
-- Present tense (Add, Fix, Update, Remove)
-- Under 50 characters
-- Conventional commit format
+	response, err := c.Generate(ctx, prompt)
+	if err != nil {
+		return CommitMessage{}, err
+	}
 
-Description requirements:
-- 2-3 sentences explaining what was changed and why
-- Include technical details about the implementation
-- Mention any test cases or validation added
-- No prefix needed just the description itself
+	msg := parseCommitMessage(response)
+	if msg.Title == "" {
+		return CommitMessage{}, ErrUnparseableResponse
+	}
+	return msg, nil
+}
 
-Git diff:
-%s`, toneInstruction, diff)
+// ErrUnparseableResponse is returned when no commit title can be read from
+// the model's response.
+var ErrUnparseableResponse = errors.New("model response does not contain a commit title")
 
+// Generate sends a single prompt to the model and returns the raw response
+// text. The response is streamed so that the first-token timeout applies
+// while the model loads and starts answering.
+func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
+	options := c.Options
 	req := GenerateRequest{
-		Model:  c.Model,
-		Prompt: prompt,
-		Stream: false,
+		Model:     c.Model,
+		Prompt:    prompt,
+		Stream:    true,
+		Options:   &options,
+		KeepAlive: c.KeepAlive,
 	}
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return CommitMessage{}, fmt.Errorf("failed to marshal request: %w", err)
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := c.BaseURL + "/api/generate"
@@ -111,48 +156,70 @@ Git diff:
 		WithField("request.prompt", req.Prompt).
 		Debugf("Making request to:%s", url)
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	resp, err := c.do(ctx, c.timeouts.Total, "POST", url, jsonData)
 	if err != nil {
-		return CommitMessage{}, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return CommitMessage{}, fmt.Errorf("failed to make request to %s: %w", url, err)
+		return "", fmt.Errorf("failed to make request to %s: %w", url, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := json.Marshal(resp.Body)
-		return CommitMessage{}, fmt.Errorf("ollama request to %s failed with status: %d, response: %s", url, resp.StatusCode, string(body))
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("ollama request to %s failed with status: %d, response: %s", url, resp.StatusCode, string(body))
 	}
 
-	var result GenerateResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return CommitMessage{}, fmt.Errorf("failed to decode response: %w", err)
+	// A streamed response is one JSON object per chunk, a non-streamed one
+	// a single object, so both decode the same way
+	var response strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk GenerateResponse
+		err := decoder.Decode(&chunk)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to decode response: %w", err)
+		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("ollama request to %s failed: %s", url, chunk.Error)
+		}
+		response.WriteString(chunk.Response)
+		if chunk.Done {
+			break
+		}
 	}
 
-	return parseCommitMessage(result.Response), nil
+	return response.String(), nil
 }
 
+// chatterPattern matches closing remarks some models add after the commit
+// message, which must not end up in the description.
+var chatterPattern = regexp.MustCompile(`(?i)^(this should|i hope|hope this|let me know|feel free|here'?s|this commit message)`)
+
 func parseCommitMessage(response string) CommitMessage {
 	// Debug: log the raw response to understand the format
 	logrus.Debugf("Raw LLM response: %q", response)
 
 	lines := strings.Split(strings.TrimSpace(response), "\n")
 
-	var title, description string
+	var title string
+	var descriptionLines []string
+	inDescription := false
 
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "TITLE:") {
-			title = strings.TrimSpace(strings.TrimPrefix(line, "TITLE:"))
-		} else if strings.HasPrefix(line, "DESCRIPTION:") {
-			description = strings.TrimSpace(strings.TrimPrefix(line, "DESCRIPTION:"))
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "TITLE:") {
+			title = strings.TrimSpace(strings.TrimPrefix(trimmed, "TITLE:"))
+			inDescription = false
+		} else if strings.HasPrefix(trimmed, "DESCRIPTION:") {
+			// The description runs until the next label and may span paragraphs
+			descriptionLines = []string{strings.TrimSpace(strings.TrimPrefix(trimmed, "DESCRIPTION:"))}
+			inDescription = true
+		} else if inDescription {
+			descriptionLines = append(descriptionLines, strings.TrimRight(line, " \t\r"))
 		}
 	}
+	description := cleanDescription(descriptionLines)
 
 	// Fallback if the LLM didn't follow the format
 	if title == "" && description == "" {
@@ -161,7 +228,7 @@ func parseCommitMessage(response string) CommitMessage {
 			title = strings.TrimSpace(lines[0])
 		}
 		if len(lines) > 1 {
-			description = strings.TrimSpace(strings.Join(lines[1:], " "))
+			description = cleanDescription(lines[1:])
 		}
 	}
 
@@ -180,30 +247,55 @@ func parseCommitMessage(response string) CommitMessage {
 	}
 }
 
+// cleanDescription joins description lines, keeping line breaks and single
+// blank lines between paragraphs, removing common indentation and dropping
+// trailing chatter paragraphs.
+func cleanDescription(lines []string) string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || lineIndent < indent {
+			indent = lineIndent
+		}
+	}
+
+	var paragraphs [][]string
+	var current []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, strings.TrimRight(line[indent:], " \t\r"))
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+
+	for len(paragraphs) > 1 && chatterPattern.MatchString(strings.TrimSpace(paragraphs[len(paragraphs)-1][0])) {
+		paragraphs = paragraphs[:len(paragraphs)-1]
+	}
+
+	joined := make([]string, 0, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		joined = append(joined, strings.Join(paragraph, "\n"))
+	}
+	return strings.Join(joined, "\n\n")
+}
+
+// getToneInstruction renders the tone template for tone. A broken override
+// falls back to the built-in custom tone so that generation still works.
 func getToneInstruction(tone string) string {
-	switch tone {
-	case "fun":
-		return "TONE INSTRUCTION: Write BOTH the title and description using a fun, playful tone with emojis and creative language while keeping it professional."
-	case "pirate":
-		return "TONE INSTRUCTION: Write BOTH the title and description in pirate speak with nautical terminology (e.g., 'Hoist', 'Plunder', 'Navigate', 'Arrr', 'matey')."
-	case "haiku":
-		return "TONE INSTRUCTION: Write the TITLE as a single-line haiku with 5-7-5 syllable structure, separating each line with ' / '. Write the description in a poetic, zen-like tone."
-	case "serious":
-		return "TONE INSTRUCTION: Write BOTH the title and description using a very serious, formal tone with technical precision and no casual language."
-	case "professional":
-		return "TONE INSTRUCTION: Write BOTH the title and description using a professional, clear tone."
-	default:
-		// Custom tone provided by user
-		return fmt.Sprintf(`TONE INSTRUCTION: Write BOTH the title and description using a %s tone. 
-
-Examples of how to apply this tone:
-- If the tone is "like a joke" or "funny": Use humor, puns, wordplay, or amusing language while keeping it understandable
-- If the tone is "dramatic": Use intense, theatrical language with strong emotions and vivid descriptions  
-- If the tone is "casual": Use relaxed, informal language like you're talking to a friend
-- If the tone is "poetic": Use metaphors, rhythm, and beautiful imagery
-- If the tone is "sarcastic": Use irony and subtle mockery while still being informative
-- If the tone is a specific style (e.g., "like Shakespeare"): Mimic the vocabulary, sentence structure, and mannerisms of that style
-
-Be creative and fully commit to this %s tone in BOTH the title and description. Don't just mention the tone - actually write in that style.`, tone, tone)
+	instruction, err := renderTone(tone)
+	if err != nil {
+		logrus.WithError(err).Warn("could not render tone template")
+		instruction, _ = renderBuiltinPrompt("tones/custom.tmpl", toneData{Tone: tone})
 	}
+	return instruction
 }
@@ -32,7 +32,7 @@ DESCRIPTION: Updated OpenAPI specifications for all endpoints.
 Added examples for request/response formats.
 Fixed validation schemas for user registration.`,
 			expectedTitle:       "Update API documentation",
-			expectedDescription: "Updated OpenAPI specifications for all endpoints.",
+			expectedDescription: "Updated OpenAPI specifications for all endpoints.\nAdded examples for request/response formats.\nFixed validation schemas for user registration.",
 		},
 		{
 			name: "Response without proper format (fallback to first line)",
@@ -40,7 +40,7 @@ Fixed validation schemas for user registration.`,
 This commit adds structured logging with different levels
 and proper error handling throughout the application.`,
 			expectedTitle:       "Add new logging functionality",
-			expectedDescription: "This commit adds structured logging with different levels and proper error handling throughout the application.",
+			expectedDescription: "This commit adds structured logging with different levels\nand proper error handling throughout the application.",
 		},
 		{
 			name:                "Single line response (fallback)",
@@ -93,6 +93,30 @@ DESCRIPTION: Second description`,
 			expectedTitle:       "Second title",
 			expectedDescription: "Second description",
 		},
+		{
+			name: "Description with paragraphs and bullet points",
+			response: `TITLE: Add retry support
+DESCRIPTION: Retries failed requests to the model server.
+
+- Back off exponentially between attempts
+- Give up after the configured timeout
+
+Let me know if you need anything else!`,
+			expectedTitle:       "Add retry support",
+			expectedDescription: "Retries failed requests to the model server.\n\n- Back off exponentially between attempts\n- Give up after the configured timeout",
+		},
+		{
+			name: "Description starting on the next line",
+			response: `TITLE: Add retry support
+DESCRIPTION:
+  - Back off exponentially
+    between attempts
+
+
+  - Give up after the timeout`,
+			expectedTitle:       "Add retry support",
+			expectedDescription: "- Back off exponentially\n  between attempts\n\n- Give up after the timeout",
+		},
 	}
 
 	for _, tt := range tests {
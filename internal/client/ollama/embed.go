@@ -0,0 +1,58 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultEmbedModel is used for embeddings when no model is configured.
+const DefaultEmbedModel = "nomic-embed-text"
+
+type EmbedRequest struct {
+	Model     string    `json:"model"`
+	Input     []string  `json:"input"`
+	KeepAlive KeepAlive `json:"keep_alive,omitempty"`
+}
+
+type EmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// Embed returns one embedding vector per input using the client's model,
+// which must be an embedding model.
+func (c *Client) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	jsonData, err := json.Marshal(EmbedRequest{Model: c.Model, Input: inputs, KeepAlive: c.KeepAlive})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := c.BaseURL + "/api/embed"
+	logrus.
+		WithField("inputs", len(inputs)).
+		Debugf("Making request to:%s", url)
+
+	resp, err := c.do(ctx, c.timeouts.Total, "POST", url, jsonData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ollama request to %s failed with status: %d, response: %s", url, resp.StatusCode, string(body))
+	}
+
+	var result EmbedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(inputs), len(result.Embeddings))
+	}
+	return result.Embeddings, nil
+}
@@ -0,0 +1,48 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("request path = %q, want /api/embed", r.URL.Path)
+		}
+		var req EmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Model != "nomic-embed-text" || len(req.Input) != 2 {
+			t.Errorf("request = %+v, want model nomic-embed-text and 2 inputs", req)
+		}
+		json.NewEncoder(w).Encode(EmbedResponse{Embeddings: [][]float32{{1, 0}, {0, 1}}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, DefaultEmbedModel)
+	result, err := client.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, [][]float32{{1, 0}, {0, 1}}) {
+		t.Errorf("Embed() = %v, want [[1 0] [0 1]]", result)
+	}
+}
+
+func TestEmbedCountMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(EmbedResponse{Embeddings: [][]float32{{1, 0}}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, DefaultEmbedModel)
+	if _, err := client.Embed(context.Background(), []string{"a", "b"}); err == nil {
+		t.Error("Embed() expected an error when the embedding count does not match")
+	}
+}
@@ -0,0 +1,89 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// GroupChanges asks the model to cluster the changes of diff into logically
+// related sets, one set per future commit. changes names each change, either
+// a file path or "path#N" for the N-th hunk of a file. Every change is
+// returned in exactly one group.
+func (c *Client) GroupChanges(ctx context.Context, changes []string, diff string) ([][]string, error) {
+	prompt := fmt.Sprintf(`The git diff below contains several unrelated changes. Group the changes into logically related sets so that each set can be committed on its own.
+
+Each change is a whole file, named by its path, or a single hunk of a file, named path#N for the N-th hunk. Hunks of the same file may go into different groups.
+
+Respond with one line per group in exactly this format:
+GROUP: [comma separated change names]
+
+Requirements:
+- Every change must appear in exactly one group
+- Keep changes that depend on each other (e.g. code and its tests) in the same group
+- Prefer fewer groups when changes are related
+
+Changes:
+%s
+
+Git diff:
+%s`, strings.Join(changes, "\n"), diff)
+
+	response, err := c.Generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := parseGroups(response, changes)
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("model response did not contain any groups")
+	}
+	return groups, nil
+}
+
+func parseGroups(response string, files []string) [][]string {
+	known := make(map[string]bool, len(files))
+	for _, f := range files {
+		known[f] = true
+	}
+
+	seen := map[string]bool{}
+	var groups [][]string
+
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "GROUP:") {
+			continue
+		}
+
+		var group []string
+		for _, f := range strings.Split(strings.TrimPrefix(line, "GROUP:"), ",") {
+			f = strings.Trim(strings.TrimSpace(f), "`\"'")
+			if !known[f] || seen[f] {
+				continue
+			}
+			seen[f] = true
+			group = append(group, f)
+		}
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	if len(groups) == 0 {
+		return nil
+	}
+
+	// Files the model forgot are committed together at the end
+	var rest []string
+	for _, f := range files {
+		if !seen[f] {
+			rest = append(rest, f)
+		}
+	}
+	if len(rest) > 0 {
+		groups = append(groups, rest)
+	}
+
+	return groups
+}
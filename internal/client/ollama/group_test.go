@@ -0,0 +1,44 @@
+package ollama
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGroups(t *testing.T) {
+	files := []string{"api/server.go", "api/server_test.go", "docs/README.md", "Makefile"}
+
+	tests := []struct {
+		name     string
+		response string
+		expected [][]string
+	}{
+		{
+			name: "Well formed groups",
+			response: `GROUP: api/server.go, api/server_test.go
+GROUP: docs/README.md, Makefile`,
+			expected: [][]string{{"api/server.go", "api/server_test.go"}, {"docs/README.md", "Makefile"}},
+		},
+		{
+			name: "Unknown and duplicate files are dropped",
+			response: `Here are the groups:
+GROUP: api/server.go, api/unknown.go
+GROUP: ` + "`api/server_test.go`" + `, api/server.go`,
+			expected: [][]string{{"api/server.go"}, {"api/server_test.go"}, {"docs/README.md", "Makefile"}},
+		},
+		{
+			name:     "No groups",
+			response: "I cannot group these files.",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseGroups(tt.response, files)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseGroups() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
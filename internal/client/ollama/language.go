@@ -0,0 +1,79 @@
+package ollama
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// languageTagPattern accepts BCP 47 style tags such as "de", "ja" or "pt-BR".
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// languageNames names the languages the prompt is most often asked for.
+// Keys are lowercase tags, a region specific name wins over the base one.
+var languageNames = map[string]string{
+	"de":    "German",
+	"en":    "English",
+	"es":    "Spanish",
+	"fr":    "French",
+	"it":    "Italian",
+	"ja":    "Japanese",
+	"ko":    "Korean",
+	"nl":    "Dutch",
+	"pl":    "Polish",
+	"pt":    "Portuguese",
+	"pt-br": "Brazilian Portuguese",
+	"pt-pt": "European Portuguese",
+	"ru":    "Russian",
+	"sv":    "Swedish",
+	"tr":    "Turkish",
+	"uk":    "Ukrainian",
+	"zh":    "Chinese",
+	"zh-cn": "Simplified Chinese",
+	"zh-tw": "Traditional Chinese",
+}
+
+// languageData is available to the language template.
+type languageData struct {
+	Language string
+	Tag      string
+}
+
+// ValidateLanguage reports whether tag looks like a language tag. An empty
+// tag selects the default, English.
+func ValidateLanguage(tag string) error {
+	if tag != "" && !languageTagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid language %q, expected a language tag such as de, ja or pt-BR", tag)
+	}
+	return nil
+}
+
+// IsEnglish reports whether tag selects English, the language the prompts
+// are written in.
+func IsEnglish(tag string) bool {
+	base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	return base == "" || base == "en"
+}
+
+// LanguageName returns the English name of the language tag, e.g. "German"
+// for "de", keeping an unknown region in parentheses.
+func LanguageName(tag string) string {
+	lower := strings.ToLower(tag)
+	if name, ok := languageNames[lower]; ok {
+		return name
+	}
+	base, _, _ := strings.Cut(lower, "-")
+	if name, ok := languageNames[base]; ok {
+		return fmt.Sprintf("%s (%s)", name, tag)
+	}
+	return fmt.Sprintf("the language with the tag %q", tag)
+}
+
+// renderLanguage renders the language instruction for tag, which is empty
+// for English.
+func renderLanguage(tag string) (string, error) {
+	if IsEnglish(tag) {
+		return "", nil
+	}
+	return renderPrompt("language.tmpl", languageData{Language: LanguageName(tag), Tag: tag})
+}
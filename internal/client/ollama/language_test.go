@@ -0,0 +1,73 @@
+package ollama
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateLanguage(t *testing.T) {
+	tests := []struct {
+		tag     string
+		wantErr bool
+	}{
+		{tag: ""},
+		{tag: "de"},
+		{tag: "ja"},
+		{tag: "pt-BR"},
+		{tag: "zh-Hant-TW"},
+		{tag: "german", wantErr: true},
+		{tag: "pt_BR", wantErr: true},
+		{tag: "d", wantErr: true},
+		{tag: "de-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			err := ValidateLanguage(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLanguage(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLanguageName(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{tag: "de", expected: "German"},
+		{tag: "pt-BR", expected: "Brazilian Portuguese"},
+		{tag: "de-AT", expected: "German (de-AT)"},
+		{tag: "xx", expected: `the language with the tag "xx"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if result := LanguageName(tt.tag); result != tt.expected {
+				t.Errorf("LanguageName(%q) = %q, want %q", tt.tag, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderCommitPromptLanguage(t *testing.T) {
+	english, err := RenderCommitPrompt("diff", "professional", PromptContext{Language: "en-US"})
+	if err != nil {
+		t.Fatalf("RenderCommitPrompt() unexpected error: %v", err)
+	}
+	if strings.Contains(english, "LANGUAGE INSTRUCTION") {
+		t.Errorf("RenderCommitPrompt() = %q, want no language instruction for English", english)
+	}
+
+	german, err := RenderCommitPrompt("diff", "professional", PromptContext{Language: "de"})
+	if err != nil {
+		t.Fatalf("RenderCommitPrompt() unexpected error: %v", err)
+	}
+	expected := `TONE INSTRUCTION: Write BOTH the title and description using a professional, clear tone.
+
+LANGUAGE INSTRUCTION: Write the title and description in German. Keep the following in English:`
+	if !strings.Contains(german, expected) {
+		t.Errorf("RenderCommitPrompt() = %q, want it to contain %q", german, expected)
+	}
+}
@@ -0,0 +1,146 @@
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+type TagsResponse struct {
+	Models []ModelInfo `json:"models"`
+}
+
+type ModelInfo struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+type ModelPullRequest struct {
+	Model  string `json:"model"`
+	Stream bool   `json:"stream"`
+}
+
+// PullProgress is one line of the streamed /api/pull response.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest"`
+	Total     int64  `json:"total"`
+	Completed int64  `json:"completed"`
+	Error     string `json:"error"`
+}
+
+// ModelNotFoundError reports that the requested model is not installed on
+// the Ollama server.
+type ModelNotFoundError struct {
+	Model     string
+	Installed []string
+}
+
+func (e *ModelNotFoundError) Error() string {
+	installed := "none"
+	if len(e.Installed) > 0 {
+		installed = strings.Join(e.Installed, ", ")
+	}
+	return fmt.Sprintf("model %q is not installed (installed models: %s), run 'ollama pull %s' or retry with --pull", e.Model, installed, e.Model)
+}
+
+// IsModelNotFound reports whether err is a ModelNotFoundError.
+func IsModelNotFound(err error) bool {
+	var notFound *ModelNotFoundError
+	return errors.As(err, &notFound)
+}
+
+// ListModels returns the names of the models installed on the server.
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	resp, err := c.do(ctx, c.timeouts.Total, "GET", c.BaseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ollama at %s: %w", c.BaseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama health check failed with status: %d", resp.StatusCode)
+	}
+
+	var tags TagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %w", err)
+	}
+
+	names := make([]string, 0, len(tags.Models))
+	for _, model := range tags.Models {
+		names = append(names, model.Name)
+	}
+	return names, nil
+}
+
+// modelInstalled reports whether model is in installed, treating a name
+// without a tag as ":latest".
+func modelInstalled(model string, installed []string) bool {
+	want := withDefaultTag(model)
+	for _, name := range installed {
+		if withDefaultTag(name) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func withDefaultTag(model string) string {
+	if strings.Contains(model, ":") {
+		return model
+	}
+	return model + ":latest"
+}
+
+// Pull downloads the client's model, calling progress for every status
+// update. It runs until the download finishes or ctx is done.
+func (c *Client) Pull(ctx context.Context, progress func(PullProgress)) error {
+	jsonData, err := json.Marshal(ModelPullRequest{Model: c.Model, Stream: true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := c.BaseURL + "/api/pull"
+	logrus.WithField("model", c.Model).Debugf("Making request to:%s", url)
+
+	// Downloads take longer than the total timeout, ctx bounds them instead
+	resp, err := c.do(ctx, 0, "POST", url, jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to make request to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ollama request to %s failed with status: %d, response: %s", url, resp.StatusCode, string(body))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	success := false
+	for scanner.Scan() {
+		var update PullProgress
+		if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			return fmt.Errorf("failed to decode pull progress: %w", err)
+		}
+		if update.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", c.Model, update.Error)
+		}
+		success = update.Status == "success"
+		progress(update)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read pull progress: %w", err)
+	}
+	if !success {
+		return fmt.Errorf("pull of %s ended before it completed", c.Model)
+	}
+	return nil
+}
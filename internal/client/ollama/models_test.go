@@ -0,0 +1,130 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func tagsServer(t *testing.T, names ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("request path = %q, want /api/tags", r.URL.Path)
+		}
+		var tags TagsResponse
+		for _, name := range names {
+			tags.Models = append(tags.Models, ModelInfo{Name: name})
+		}
+		json.NewEncoder(w).Encode(tags)
+	}))
+}
+
+func TestHealthCheck(t *testing.T) {
+	server := tagsServer(t, "llama3.2:latest", "qwen2.5-coder:7b")
+	defer server.Close()
+
+	tests := []struct {
+		model     string
+		wantFound bool
+	}{
+		{model: "llama3.2", wantFound: true},
+		{model: "llama3.2:latest", wantFound: true},
+		{model: "qwen2.5-coder:7b", wantFound: true},
+		{model: "qwen2.5-coder", wantFound: false},
+		{model: "mistral", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			err := NewClient(server.URL, tt.model).HealthCheck(context.Background())
+			if tt.wantFound {
+				if err != nil {
+					t.Errorf("HealthCheck() unexpected error: %v", err)
+				}
+				return
+			}
+
+			var notFound *ModelNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Fatalf("HealthCheck() error = %v, want a ModelNotFoundError", err)
+			}
+			if !reflect.DeepEqual(notFound.Installed, []string{"llama3.2:latest", "qwen2.5-coder:7b"}) {
+				t.Errorf("Installed = %v, want the installed models", notFound.Installed)
+			}
+		})
+	}
+}
+
+func TestHealthCheckServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := NewClient(server.URL, "llama3.2").HealthCheck(context.Background())
+	if err == nil || IsModelNotFound(err) {
+		t.Errorf("HealthCheck() error = %v, want a status error", err)
+	}
+}
+
+func TestPull(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		wantErr bool
+	}{
+		{
+			name: "Completed pull",
+			lines: []string{
+				`{"status":"pulling manifest"}`,
+				`{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":50}`,
+				`{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":100}`,
+				`{"status":"success"}`,
+			},
+		},
+		{
+			name:    "Error while pulling",
+			lines:   []string{`{"status":"pulling manifest"}`, `{"error":"pull model manifest: file does not exist"}`},
+			wantErr: true,
+		},
+		{
+			name:    "Stream ends early",
+			lines:   []string{`{"status":"pulling manifest"}`},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req ModelPullRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("failed to decode request: %v", err)
+				}
+				if r.URL.Path != "/api/pull" || req.Model != "mistral" || !req.Stream {
+					t.Errorf("request = %s %+v, want a streamed pull of mistral", r.URL.Path, req)
+				}
+				for _, line := range tt.lines {
+					fmt.Fprintln(w, line)
+				}
+			}))
+			defer server.Close()
+
+			var updates []PullProgress
+			err := NewClient(server.URL, "mistral").Pull(context.Background(), func(p PullProgress) {
+				updates = append(updates, p)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Pull() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(updates) != len(tt.lines) {
+				t.Errorf("Pull() reported %d updates, want %d", len(updates), len(tt.lines))
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Defaults that keep generated messages reproducible for the same diff.
+const (
+	DefaultTemperature = 0.2
+	DefaultSeed        = 42
+)
+
+// Options are the Ollama model parameters sent with every generate request.
+// Unset fields use the model's own defaults.
+type Options struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+	// NumCtx is the context window in tokens, raise it for big diffs
+	NumCtx int `json:"num_ctx,omitempty"`
+	// NumPredict limits the number of generated tokens
+	NumPredict int      `json:"num_predict,omitempty"`
+	Stop       []string `json:"stop,omitempty"`
+}
+
+// DefaultOptions returns the deterministic defaults: a low temperature and a
+// fixed seed.
+func DefaultOptions() Options {
+	temperature := DefaultTemperature
+	seed := DefaultSeed
+	return Options{Temperature: &temperature, Seed: &seed}
+}
+
+// KeepAlive is how long Ollama keeps the model loaded after a request,
+// either a duration such as "10m" or a number of seconds, where a negative
+// number keeps it loaded indefinitely. Empty uses the server default.
+type KeepAlive string
+
+// Validate reports whether k is a duration or a number of seconds.
+func (k KeepAlive) Validate() error {
+	if k == "" {
+		return nil
+	}
+	if _, err := strconv.Atoi(string(k)); err == nil {
+		return nil
+	}
+	if _, err := time.ParseDuration(string(k)); err != nil {
+		return fmt.Errorf("invalid keep-alive %q, expected a duration such as 10m or a number of seconds", string(k))
+	}
+	return nil
+}
+
+// MarshalJSON sends numbers as JSON numbers, which Ollama reads as seconds.
+func (k KeepAlive) MarshalJSON() ([]byte, error) {
+	if seconds, err := strconv.Atoi(string(k)); err == nil {
+		return json.Marshal(seconds)
+	}
+	return json.Marshal(string(k))
+}
+
+// defaultOptions and defaultKeepAlive are used by clients created with
+// NewClient, see SetModelOptions.
+var (
+	defaultOptions   = DefaultOptions()
+	defaultKeepAlive KeepAlive
+)
+
+// SetModelOptions sets the model options and keep-alive used by clients
+// created afterwards.
+func SetModelOptions(options Options, keepAlive KeepAlive) {
+	defaultOptions = options
+	defaultKeepAlive = keepAlive
+}
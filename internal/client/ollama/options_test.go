@@ -0,0 +1,78 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenerateSendsOptions(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(GenerateResponse{Response: "ok", Done: true})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "llama3.2")
+	client.Options.NumCtx = 8192
+	client.Options.Stop = []string{"\n\n\n"}
+	client.KeepAlive = "-1"
+
+	if _, err := client.Generate(context.Background(), "prompt"); err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	options, ok := body["options"].(map[string]any)
+	if !ok {
+		t.Fatalf("request = %v, want options", body)
+	}
+	expected := map[string]any{"temperature": DefaultTemperature, "seed": float64(DefaultSeed), "num_ctx": float64(8192)}
+	for key, value := range expected {
+		if options[key] != value {
+			t.Errorf("options[%q] = %v, want %v", key, options[key], value)
+		}
+	}
+	if _, ok := options["top_p"]; ok {
+		t.Errorf("options = %v, want top_p left unset", options)
+	}
+	if body["keep_alive"] != float64(-1) {
+		t.Errorf("keep_alive = %v, want -1 as a number", body["keep_alive"])
+	}
+}
+
+func TestKeepAlive(t *testing.T) {
+	tests := []struct {
+		value    KeepAlive
+		expected string
+		wantErr  bool
+	}{
+		{value: "10m", expected: `"10m"`},
+		{value: "300", expected: `300`},
+		{value: "-1", expected: `-1`},
+		{value: "forever", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.value), func(t *testing.T) {
+			err := tt.value.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Marshal() = %s, want %s", data, tt.expected)
+			}
+		})
+	}
+}
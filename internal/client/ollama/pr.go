@@ -0,0 +1,92 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PullRequest is a generated pull request title and markdown body.
+type PullRequest struct {
+	Title string
+	Body  string
+}
+
+// GeneratePullRequest writes a pull request title and markdown description
+// for the given commit log and combined diff. When template is not empty the
+// body must follow its section headings.
+func (c *Client) GeneratePullRequest(ctx context.Context, commits, diff, ticket, template string) (PullRequest, error) {
+	structure := `The body must contain these markdown sections:
+## Summary
+## Changes
+## Testing`
+	if strings.TrimSpace(template) != "" {
+		structure = fmt.Sprintf(`The body must follow this template, keeping every section heading and filling in each section:
+%s`, template)
+	}
+
+	ticketInstruction := "There is no ticket for this change."
+	if ticket != "" {
+		ticketInstruction = fmt.Sprintf("Reference the ticket %s in the body.", ticket)
+	}
+
+	prompt := fmt.Sprintf(`Based on the commits and git diff below, write a pull request title and description.
+
+Respond with exactly this format:
+TITLE: [short pull request title]
+BODY:
+[markdown description]
+
+Title requirements:
+- Present tense (Add, Fix, Update, Remove)
+- Under 72 characters
+
+Body requirements:
+- Summarize what the branch changes and why
+- List the notable changes as bullet points
+- Describe how the changes were or should be tested
+- %s
+
+%s
+
+Commits:
+%s
+
+Git diff:
+%s`, ticketInstruction, structure, commits, diff)
+
+	response, err := c.Generate(ctx, prompt)
+	if err != nil {
+		return PullRequest{}, err
+	}
+
+	pr := parsePullRequest(response)
+	if pr.Title == "" {
+		return PullRequest{}, fmt.Errorf("model response did not contain a title")
+	}
+	return pr, nil
+}
+
+func parsePullRequest(response string) PullRequest {
+	var pr PullRequest
+	var body []string
+	inBody := false
+
+	for _, line := range strings.Split(strings.TrimSpace(response), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !inBody && strings.HasPrefix(trimmed, "TITLE:"):
+			pr.Title = strings.TrimSpace(strings.TrimPrefix(trimmed, "TITLE:"))
+		case !inBody && strings.HasPrefix(trimmed, "BODY:"):
+			inBody = true
+			if rest := strings.TrimSpace(strings.TrimPrefix(trimmed, "BODY:")); rest != "" {
+				body = append(body, rest)
+			}
+		case inBody:
+			body = append(body, strings.TrimRight(line, " \t"))
+		}
+	}
+
+	pr.Body = strings.TrimSpace(strings.Join(body, "\n"))
+	return pr
+}
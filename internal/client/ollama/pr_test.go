@@ -0,0 +1,55 @@
+package ollama
+
+import (
+	"testing"
+)
+
+func TestParsePullRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		expectedTitle string
+		expectedBody  string
+	}{
+		{
+			name: "Title and multi-line body",
+			response: `TITLE: Add rate limiting to the API
+BODY:
+## Summary
+Adds a token bucket limiter.
+
+## Changes
+- Add limiter middleware
+- Add configuration`,
+			expectedTitle: "Add rate limiting to the API",
+			expectedBody:  "## Summary\nAdds a token bucket limiter.\n\n## Changes\n- Add limiter middleware\n- Add configuration",
+		},
+		{
+			name: "Leading chatter and inline body start",
+			response: `Sure, here is the pull request:
+
+TITLE: Fix login redirect
+BODY: Fixes the redirect loop after login.`,
+			expectedTitle: "Fix login redirect",
+			expectedBody:  "Fixes the redirect loop after login.",
+		},
+		{
+			name:          "Missing format",
+			response:      "This branch fixes things.",
+			expectedTitle: "",
+			expectedBody:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parsePullRequest(tt.response)
+			if result.Title != tt.expectedTitle {
+				t.Errorf("parsePullRequest().Title = %q, want %q", result.Title, tt.expectedTitle)
+			}
+			if result.Body != tt.expectedBody {
+				t.Errorf("parsePullRequest().Body = %q, want %q", result.Body, tt.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package ollama
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultPrompts holds the built-in prompt templates. Files with the same
+// name in the prompt directory take precedence.
+//
+//go:embed prompts
+var defaultPrompts embed.FS
+
+// promptDir is the directory searched for prompt overrides, see SetPromptDir.
+var promptDir string
+
+// toneNamePattern restricts tone names that are looked up as template files.
+var toneNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// SetPromptDir sets the directory whose commit.tmpl and tones/*.tmpl files
+// override the built-in prompts. An empty dir uses the built-ins only.
+func SetPromptDir(dir string) {
+	promptDir = dir
+}
+
+// PromptData is available to the commit prompt template.
+type PromptData struct {
+	// ToneInstruction is the rendered tone template
+	ToneInstruction string
+	// LanguageInstruction is the rendered language template, empty for English
+	LanguageInstruction string
+	State               string
+	Style               string
+	Examples            []string
+	Branch              string
+	Ticket              string
+	Files               []string
+	Stats               string
+	Diff                string
+}
+
+// toneData is available to the tone templates.
+type toneData struct {
+	// Tone is the tone name as given, e.g. "like a pirate"
+	Tone string
+	// Instruction, Examples and Constraints are set for config presets
+	Instruction string
+	Examples    []string
+	Constraints []string
+}
+
+// RenderCommitPrompt renders the commit message prompt for diff without
+// calling the model.
+func RenderCommitPrompt(diff, tone string, pc PromptContext) (string, error) {
+	toneInstruction, err := renderTone(tone)
+	if err != nil {
+		return "", err
+	}
+	languageInstruction, err := renderLanguage(pc.Language)
+	if err != nil {
+		return "", err
+	}
+
+	return renderPrompt("commit.tmpl", PromptData{
+		ToneInstruction:     toneInstruction,
+		LanguageInstruction: languageInstruction,
+		State:               pc.State,
+		Style:               pc.Style,
+		Examples:            pc.Examples,
+		Branch:              pc.Branch,
+		Ticket:              pc.Ticket,
+		Files:               pc.Files,
+		Stats:               pc.Stats,
+		Diff:                diff,
+	})
+}
+
+// renderTone renders a config preset with tones/preset.tmpl, otherwise
+// tones/<tone>.tmpl, or tones/custom.tmpl for tones without a template.
+func renderTone(tone string) (string, error) {
+	if preset, ok := tonePresets[tone]; ok {
+		return renderPrompt("tones/preset.tmpl", toneData{
+			Tone:        preset.Name,
+			Instruction: preset.Instruction,
+			Examples:    preset.Examples,
+			Constraints: preset.Constraints,
+		})
+	}
+
+	name := "tones/custom.tmpl"
+	if toneNamePattern.MatchString(tone) && promptExists("tones/"+tone+".tmpl") {
+		name = "tones/" + tone + ".tmpl"
+	}
+	return renderPrompt(name, toneData{Tone: tone})
+}
+
+func renderPrompt(name string, data any) (string, error) {
+	text, err := readPrompt(name)
+	if err != nil {
+		return "", err
+	}
+	return executePrompt(name, text, data)
+}
+
+// renderBuiltinPrompt renders the built-in template name, ignoring overrides.
+func renderBuiltinPrompt(name string, data any) (string, error) {
+	text, err := defaultPrompts.ReadFile("prompts/" + name)
+	if err != nil {
+		return "", fmt.Errorf("unknown prompt template %s", name)
+	}
+	return executePrompt(name, string(text), data)
+}
+
+func executePrompt(name, text string, data any) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prompt template %s: %w", name, err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s: %w", name, err)
+	}
+	// Template files end with a newline that is not part of the prompt
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// readPrompt returns the override for name from promptDir, falling back to
+// the built-in template.
+func readPrompt(name string) (string, error) {
+	if promptDir != "" {
+		path := filepath.Join(promptDir, filepath.FromSlash(name))
+		data, err := os.ReadFile(path)
+		if err == nil {
+			logrus.WithField("path", path).Debug("using prompt override")
+			return string(data), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read prompt template %s: %w", path, err)
+		}
+	}
+
+	data, err := defaultPrompts.ReadFile("prompts/" + name)
+	if err != nil {
+		return "", fmt.Errorf("unknown prompt template %s", name)
+	}
+	return string(data), nil
+}
+
+func promptExists(name string) bool {
+	_, err := readPrompt(name)
+	return err == nil
+}
@@ -0,0 +1,111 @@
+package ollama
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderCommitPrompt(t *testing.T) {
+	prompt, err := RenderCommitPrompt("diff --git a/x b/x", "professional", PromptContext{})
+	if err != nil {
+		t.Fatalf("RenderCommitPrompt() unexpected error: %v", err)
+	}
+
+	expectedStart := `Based on the git diff below, generate a commit message with both a title and description.
+
+TONE INSTRUCTION: Write BOTH the title and description using a professional, clear tone.
+
+Respond with exactly this format:`
+	if !strings.HasPrefix(prompt, expectedStart) {
+		t.Errorf("RenderCommitPrompt() = %q, want prefix %q", prompt, expectedStart)
+	}
+	if !strings.HasSuffix(prompt, "Git diff:\ndiff --git a/x b/x") {
+		t.Errorf("RenderCommitPrompt() = %q, want the diff at the end", prompt)
+	}
+}
+
+func TestRenderCommitPromptWithContext(t *testing.T) {
+	pc := PromptContext{
+		Style:    "- Do not use emojis",
+		Examples: []string{"fix(api): handle empty pages", "feat: add login\n\nAdds a login form."},
+	}
+
+	prompt, err := RenderCommitPrompt("diff", "professional", pc)
+	if err != nil {
+		t.Fatalf("RenderCommitPrompt() unexpected error: %v", err)
+	}
+
+	expected := `TONE INSTRUCTION: Write BOTH the title and description using a professional, clear tone.
+
+STYLE INSTRUCTION: Follow the commit message conventions of this repository, they take precedence over the requirements below:
+- Do not use emojis
+
+Examples of commit messages from this repository, match their style but not their content:
+---
+fix(api): handle empty pages
+---
+feat: add login
+
+Adds a login form.
+---
+
+Respond with exactly this format:`
+	if !strings.Contains(prompt, expected) {
+		t.Errorf("RenderCommitPrompt() = %q, want it to contain %q", prompt, expected)
+	}
+}
+
+func TestPromptOverrides(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "tones"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"commit.tmpl":      "{{.ToneInstruction}}\nBranch {{.Branch}}, ticket {{.Ticket}}, {{len .Files}} file(s): {{.Stats}}\n{{.Diff}}\n",
+		"tones/terse.tmpl": "Be terse, {{.Tone}}.\n",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	SetPromptDir(dir)
+	defer SetPromptDir("")
+
+	pc := PromptContext{Branch: "feat/AUTH-12-login", Ticket: "AUTH-12", Files: []string{"a.go", "b.go"}, Stats: "2 files changed"}
+	prompt, err := RenderCommitPrompt("the diff", "terse", pc)
+	if err != nil {
+		t.Fatalf("RenderCommitPrompt() unexpected error: %v", err)
+	}
+
+	expected := "Be terse, terse.\nBranch feat/AUTH-12-login, ticket AUTH-12, 2 file(s): 2 files changed\nthe diff"
+	if prompt != expected {
+		t.Errorf("RenderCommitPrompt() = %q, want %q", prompt, expected)
+	}
+
+	// Built-in tones are still available next to the overrides
+	if instruction := getToneInstruction("pirate"); !strings.Contains(instruction, "pirate speak") {
+		t.Errorf("getToneInstruction(pirate) = %q, want the built-in pirate tone", instruction)
+	}
+}
+
+func TestBrokenToneOverrideFallsBack(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "tones"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tones", "custom.tmpl"), []byte("{{.Missing"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	SetPromptDir(dir)
+	defer SetPromptDir("")
+
+	instruction := getToneInstruction("casual")
+	if !strings.HasPrefix(instruction, "TONE INSTRUCTION: Write BOTH the title and description using a casual tone.") {
+		t.Errorf("getToneInstruction() = %q, want the built-in custom tone", instruction)
+	}
+}
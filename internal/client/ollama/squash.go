@@ -0,0 +1,55 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+)
+
+// GenerateSquashMessage consolidates the messages of several commits and
+// their combined diff into a single commit message for a squash merge.
+func (c *Client) GenerateSquashMessage(ctx context.Context, commits, diff, tone, language string) (CommitMessage, error) {
+	toneInstruction := getToneInstruction(tone)
+	languageInstruction, err := renderLanguage(language)
+	if err != nil {
+		return CommitMessage{}, err
+	}
+	if languageInstruction != "" {
+		toneInstruction += "\n\n" + languageInstruction
+	}
+
+	prompt := fmt.Sprintf(`The commits below are about to be squashed into a single commit. Based on their messages and the combined git diff, generate one consolidated commit message with both a title and description.
+
+%s
+
+Respond with exactly this format:
+TITLE: [short commit title]
+DESCRIPTION: [detailed description]
+
+Title requirements:
+- Present tense (Add, Fix, Update, Remove)
+- Under 50 characters
+- Conventional commit format
+- Describe the overall change, not the last commit
+
+Description requirements:
+- 2-4 sentences summarizing what the branch changes and why
+- Fold fixups, typo fixes and review feedback into the overall change
+- No prefix needed just the description itself
+
+Commits:
+%s
+
+Git diff:
+%s`, toneInstruction, commits, diff)
+
+	response, err := c.Generate(ctx, prompt)
+	if err != nil {
+		return CommitMessage{}, err
+	}
+
+	msg := parseCommitMessage(response)
+	if msg.Title == "" {
+		return CommitMessage{}, ErrUnparseableResponse
+	}
+	return msg, nil
+}
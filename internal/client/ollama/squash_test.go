@@ -0,0 +1,22 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenerateSquashMessageWithoutTitle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(GenerateResponse{Response: "   ", Done: true})
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "llama3.2").GenerateSquashMessage(context.Background(), "log", "diff", "professional", "")
+	if !errors.Is(err, ErrUnparseableResponse) {
+		t.Errorf("GenerateSquashMessage() error = %v, want ErrUnparseableResponse", err)
+	}
+}
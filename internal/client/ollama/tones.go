@@ -0,0 +1,120 @@
+package ollama
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sources a tone can come from
+const (
+	ToneSourceBuiltin  = "built-in"
+	ToneSourceTemplate = "template"
+	ToneSourceConfig   = "config"
+)
+
+// builtinToneDescriptions describes the tones shipped in prompts/tones.
+var builtinToneDescriptions = map[string]string{
+	"professional": "Clear and professional (default)",
+	"fun":          "Playful, with emojis and creative language",
+	"pirate":       "Pirate speak with nautical terminology",
+	"haiku":        "Title as a 5-7-5 haiku, zen-like description",
+	"serious":      "Formal and technically precise",
+}
+
+// Tone is a named tone preset.
+type Tone struct {
+	Name        string
+	Description string
+	// Instruction tells the model how to write in this tone
+	Instruction string
+	// Examples are commit messages written in this tone
+	Examples []string
+	// Constraints are additional rules such as length limits
+	Constraints []string
+	// Source is one of the ToneSource constants
+	Source string
+}
+
+// tonePresets are the tones defined in the config file, see SetTonePresets.
+var tonePresets = map[string]Tone{}
+
+// SetTonePresets registers tone presets, replacing earlier ones. Presets
+// take precedence over tone templates of the same name.
+func SetTonePresets(tones []Tone) {
+	tonePresets = make(map[string]Tone, len(tones))
+	for _, tone := range tones {
+		tone.Source = ToneSourceConfig
+		tonePresets[tone.Name] = tone
+	}
+}
+
+// Validate reports problems with a tone preset definition.
+func (t Tone) Validate() error {
+	var problems []string
+	if !toneNamePattern.MatchString(t.Name) {
+		problems = append(problems, "name must only contain lowercase letters, digits, '-' and '_'")
+	}
+	if t.Name == "custom" || t.Name == "preset" {
+		problems = append(problems, fmt.Sprintf("name %q is reserved", t.Name))
+	}
+	if strings.TrimSpace(t.Instruction) == "" {
+		problems = append(problems, "instruction must not be empty")
+	}
+	for i, example := range t.Examples {
+		if strings.TrimSpace(example) == "" {
+			problems = append(problems, fmt.Sprintf("example %d is empty", i+1))
+		}
+	}
+	for i, constraint := range t.Constraints {
+		if strings.TrimSpace(constraint) == "" {
+			problems = append(problems, fmt.Sprintf("constraint %d is empty", i+1))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid tone %q: %s", t.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ListTones returns the built-in tones, tone templates from the prompt
+// directory and config presets. A later source hides an earlier tone of
+// the same name.
+func ListTones() []Tone {
+	tones := map[string]Tone{}
+	for name, description := range builtinToneDescriptions {
+		tones[name] = Tone{Name: name, Description: description, Source: ToneSourceBuiltin}
+	}
+
+	if promptDir != "" {
+		entries, err := os.ReadDir(filepath.Join(promptDir, "tones"))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logrus.WithError(err).Warn("could not read tone templates")
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), ".tmpl")
+			if !ok || name == "custom" || name == "preset" || !toneNamePattern.MatchString(name) {
+				continue
+			}
+			tones[name] = Tone{Name: name, Description: "Template " + filepath.Join(promptDir, "tones", entry.Name()), Source: ToneSourceTemplate}
+		}
+	}
+
+	for name, tone := range tonePresets {
+		tones[name] = tone
+	}
+
+	list := make([]Tone, 0, len(tones))
+	for _, tone := range tones {
+		list = append(list, tone)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
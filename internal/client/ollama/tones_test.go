@@ -0,0 +1,114 @@
+package ollama
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestToneValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tone    Tone
+		wantErr bool
+	}{
+		{
+			name: "Valid preset",
+			tone: Tone{Name: "release-notes", Instruction: "Write for end users.", Examples: []string{"Add dark mode"}},
+		},
+		{
+			name:    "Name with spaces",
+			tone:    Tone{Name: "release notes", Instruction: "Write for end users."},
+			wantErr: true,
+		},
+		{
+			name:    "Uppercase name",
+			tone:    Tone{Name: "Terse", Instruction: "Be brief."},
+			wantErr: true,
+		},
+		{
+			name:    "Reserved name",
+			tone:    Tone{Name: "custom", Instruction: "Be brief."},
+			wantErr: true,
+		},
+		{
+			name:    "Missing instruction",
+			tone:    Tone{Name: "terse"},
+			wantErr: true,
+		},
+		{
+			name:    "Empty constraint",
+			tone:    Tone{Name: "terse", Instruction: "Be brief.", Constraints: []string{" "}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tone.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTonePresetInstruction(t *testing.T) {
+	SetTonePresets([]Tone{{
+		Name:        "terse",
+		Instruction: "Write as briefly as possible.",
+		Examples:    []string{"fix: handle nil config"},
+		Constraints: []string{"Keep the title under 50 characters"},
+	}})
+	defer SetTonePresets(nil)
+
+	expected := `TONE INSTRUCTION: Write as briefly as possible.
+
+Constraints:
+- Keep the title under 50 characters
+
+Example commit messages in this tone:
+- fix: handle nil config`
+	if result := getToneInstruction("terse"); result != expected {
+		t.Errorf("getToneInstruction(%q) = %q, want %q", "terse", result, expected)
+	}
+}
+
+func TestListTones(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "tones"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"limerick.tmpl", "custom.tmpl", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, "tones", name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	SetPromptDir(dir)
+	defer SetPromptDir("")
+	SetTonePresets([]Tone{{Name: "fun", Description: "Team fun", Instruction: "Be fun."}})
+	defer SetTonePresets(nil)
+
+	sources := map[string]string{}
+	for _, tone := range ListTones() {
+		sources[tone.Name] = tone.Source
+	}
+
+	expected := map[string]string{
+		"professional": ToneSourceBuiltin,
+		"fun":          ToneSourceConfig,
+		"pirate":       ToneSourceBuiltin,
+		"haiku":        ToneSourceBuiltin,
+		"serious":      ToneSourceBuiltin,
+		"limerick":     ToneSourceTemplate,
+	}
+	if len(sources) != len(expected) {
+		t.Errorf("ListTones() = %v, want %v", sources, expected)
+	}
+	for name, source := range expected {
+		if sources[name] != source {
+			t.Errorf("ListTones() source of %q = %q, want %q", name, sources[name], source)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Timeouts bound the requests sent to Ollama. Zero disables a timeout.
+type Timeouts struct {
+	// Connect limits establishing the connection
+	Connect time.Duration
+	// FirstToken limits the wait for the first response bytes, which
+	// includes loading the model
+	FirstToken time.Duration
+	// Total limits a whole request including its retries and reading the
+	// response
+	Total time.Duration
+}
+
+// DefaultTimeouts leave room for loading a model from disk.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Connect:    10 * time.Second,
+		FirstToken: 2 * time.Minute,
+		Total:      3 * time.Minute,
+	}
+}
+
+// RetryPolicy controls how often transient failures such as a refused
+// connection while Ollama starts or a 503 while the model loads are retried.
+type RetryPolicy struct {
+	// Retries is the number of attempts after the first one
+	Retries int
+	// BaseDelay is the delay before the first retry, doubling with every
+	// further retry up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetryPolicy retries three times, waiting up to about four seconds
+// in total.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{Retries: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 8 * time.Second}
+}
+
+// defaultTimeouts and defaultRetryPolicy are used by clients created with
+// NewClient, see SetTimeouts.
+var (
+	defaultTimeouts    = DefaultTimeouts()
+	defaultRetryPolicy = DefaultRetryPolicy()
+)
+
+// SetTimeouts sets the timeouts and retry policy of clients created
+// afterwards.
+func SetTimeouts(timeouts Timeouts, retry RetryPolicy) {
+	defaultTimeouts = timeouts
+	defaultRetryPolicy = retry
+}
+
+// newHTTPClient returns an HTTP client enforcing the connect and first token
+// timeouts. The total timeout spans retries, so do enforces it.
+func newHTTPClient(timeouts Timeouts) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: timeouts.Connect, KeepAlive: 30 * time.Second}).DialContext
+	transport.ResponseHeaderTimeout = timeouts.FirstToken
+	return &http.Client{Transport: transport}
+}
+
+// backoff returns the jittered delay before retry number attempt, counting
+// from zero: a random duration between half and all of the exponential delay.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// transient reports whether a request that failed with err or resp is
+// worth retrying.
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// do sends a request with an optional JSON body, retrying transient failures
+// with jittered exponential backoff. A positive total bounds all attempts
+// together with reading the response body, which must be closed.
+func (c *Client) do(ctx context.Context, total time.Duration, method, url string, body []byte) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if total > 0 {
+		ctx, cancel = context.WithTimeout(ctx, total)
+	}
+
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		httpReq, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if body != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.client.Do(httpReq)
+		if attempt >= c.Retry.Retries || !transient(resp, err) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		reason := "connection failed"
+		if err == nil {
+			reason = resp.Status
+			resp.Body.Close()
+		}
+		delay := c.Retry.backoff(attempt)
+		logrus.
+			WithField("url", url).
+			WithField("attempt", attempt+1).
+			WithField("delay", delay).
+			Debugf("retrying request: %s", reason)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// cancelBody cancels the context of a request once its body is closed, so
+// that the total timeout also covers reading the response.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,195 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransientErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		status       int
+		retries      int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "Model loading", failures: 2, status: http.StatusServiceUnavailable, retries: 3, wantAttempts: 3},
+		{name: "Retries exhausted", failures: 5, status: http.StatusServiceUnavailable, retries: 2, wantAttempts: 3, wantErr: true},
+		{name: "Server error is not retried", failures: 1, status: http.StatusInternalServerError, retries: 3, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts <= tt.failures {
+					w.WriteHeader(tt.status)
+					return
+				}
+				json.NewEncoder(w).Encode(GenerateResponse{Response: "ok", Done: true})
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "llama3.2")
+			client.Retry = RetryPolicy{Retries: tt.retries, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+			_, err := client.Generate(context.Background(), "prompt")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("Generate() made %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryConnectionRefused(t *testing.T) {
+	// Reserve a port and close it so that connecting is refused
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	dials := 0
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials++
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	client := NewClient(url, "llama3.2")
+	client.client = &http.Client{Transport: transport}
+	client.Retry = RetryPolicy{Retries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	if _, err := client.ListModels(context.Background()); err == nil {
+		t.Fatal("ListModels() expected an error for a refused connection")
+	}
+	if dials != 3 {
+		t.Errorf("ListModels() dialed %d times, want 3", dials)
+	}
+}
+
+func TestHealthCheckIsNotRetried(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	dials := 0
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials++
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	client := NewClient(url, "llama3.2")
+	client.client = &http.Client{Transport: transport}
+	client.Retry = RetryPolicy{Retries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	if err := client.HealthCheck(context.Background()); err == nil {
+		t.Fatal("HealthCheck() expected an error for a refused connection")
+	}
+	if dials != 1 {
+		t.Errorf("HealthCheck() dialed %d times, want 1", dials)
+	}
+}
+
+func TestHealthCheckConnectTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "llama3.2")
+	client.timeouts.Connect = 50 * time.Millisecond
+	start := time.Now()
+	if err := client.HealthCheck(context.Background()); err == nil {
+		t.Fatal("HealthCheck() expected an error when the server does not answer")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("HealthCheck() took %v, want the connect timeout to stop it", elapsed)
+	}
+}
+
+func TestTotalTimeoutSpansRetries(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "llama3.2")
+	client.Retry = RetryPolicy{Retries: 20, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	client.timeouts.Total = 100 * time.Millisecond
+	if _, err := client.Generate(context.Background(), "prompt"); err == nil {
+		t.Fatal("Generate() expected an error when the total timeout passes")
+	}
+	if n := attempts.Load(); n > 5 {
+		t.Errorf("Generate() made %d attempts, want the total timeout to stop retrying", n)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{Retries: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	limits := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+
+	for attempt, limit := range limits {
+		for range 20 {
+			delay := policy.backoff(attempt)
+			if delay < limit/2 || delay > limit {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, delay, limit/2, limit)
+			}
+		}
+	}
+}
+
+func TestFirstTokenTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "llama3.2")
+	client.client = newHTTPClient(Timeouts{FirstToken: 20 * time.Millisecond})
+	if _, err := client.Generate(context.Background(), "prompt"); err == nil {
+		t.Error("Generate() expected an error when the first token takes too long")
+	}
+}
+
+func TestGenerateStreamed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if !req.Stream {
+			t.Error("request stream = false, want a streamed response")
+		}
+		for _, chunk := range []string{"TITLE: ", "feat: add", " login"} {
+			fmt.Fprintf(w, "{\"response\":%q,\"done\":false}\n", chunk)
+		}
+		fmt.Fprintln(w, `{"response":"","done":true}`)
+	}))
+	defer server.Close()
+
+	response, err := NewClient(server.URL, "llama3.2").Generate(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+	if response != "TITLE: feat: add login" {
+		t.Errorf("Generate() = %q, want the joined chunks", response)
+	}
+}
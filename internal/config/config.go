@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// RepoFile is the name of the per-repository config file, looked up in the
+// root of the working tree.
+const RepoFile = ".snippety.json"
+
+// Config holds settings read from the user and repository config files.
+// Values in the repository file override the user file.
+type Config struct {
+	Branch    BranchConfig    `json:"branch"`
+	Pairing   PairingConfig   `json:"pairing"`
+	Message   MessageConfig   `json:"message"`
+	Style     StyleConfig     `json:"style"`
+	Retrieval RetrievalConfig `json:"retrieval"`
+	Prompts   PromptsConfig   `json:"prompts"`
+	Ollama    OllamaConfig    `json:"ollama"`
+	Cache     CacheConfig     `json:"cache"`
+	// Tones are named tone presets, keyed by name
+	Tones map[string]ToneConfig `json:"tones"`
+}
+
+// BranchConfig configures the branch command.
+type BranchConfig struct {
+	// Pattern is the branch name template, e.g. "{type}/{TICKET}-{slug}"
+	Pattern string `json:"pattern"`
+}
+
+// PairingConfig lists the people the user commonly pairs with.
+type PairingConfig struct {
+	// Partners are "Name <email>" identities offered as co-authors in
+	// interactive mode
+	Partners []string `json:"partners"`
+}
+
+// MessageConfig configures how commit messages are written.
+type MessageConfig struct {
+	// Wrap is the width the message body is wrapped at, 0 disables wrapping.
+	// It is a pointer so that an explicit 0 can be told apart from unset.
+	Wrap *int `json:"wrap"`
+	// Language is the language tag messages are written in, e.g. "de"
+	Language string `json:"language"`
+}
+
+// StyleConfig configures learning the commit style from git history.
+type StyleConfig struct {
+	// Learn adds the repository's commit conventions to the prompt
+	Learn bool `json:"learn"`
+}
+
+// RetrievalConfig configures retrieving similar past commits as examples.
+type RetrievalConfig struct {
+	// TopK is how many similar commits are added to the prompt
+	TopK int `json:"top_k"`
+	// Model is the Ollama embedding model
+	Model string `json:"model"`
+}
+
+// PromptsConfig configures the prompt templates.
+type PromptsConfig struct {
+	// Dir holds commit.tmpl and tones/*.tmpl overriding the built-in
+	// prompts. Relative paths are resolved against the repository root.
+	Dir string `json:"dir"`
+}
+
+// OllamaConfig configures the requests sent to Ollama.
+type OllamaConfig struct {
+	// Model is the model used for generation
+	Model string `json:"model"`
+	// FallbackModels are tried in order when Model is missing or fails
+	FallbackModels []string      `json:"fallback_models"`
+	Options        OllamaOptions `json:"options"`
+	// KeepAlive is how long the model stays loaded, e.g. "10m"
+	KeepAlive string         `json:"keep_alive"`
+	Timeouts  TimeoutsConfig `json:"timeouts"`
+	// Retries is how often transient failures are retried
+	Retries *int `json:"retries"`
+}
+
+// TimeoutsConfig holds durations such as "30s" or "2m", "0" disables a
+// timeout.
+type TimeoutsConfig struct {
+	Connect    string `json:"connect"`
+	FirstToken string `json:"first_token"`
+	Total      string `json:"total"`
+}
+
+// OllamaOptions are the model parameters, unset values keep the defaults.
+type OllamaOptions struct {
+	Temperature *float64 `json:"temperature"`
+	TopP        *float64 `json:"top_p"`
+	Seed        *int     `json:"seed"`
+	NumCtx      *int     `json:"num_ctx"`
+	NumPredict  *int     `json:"num_predict"`
+	Stop        []string `json:"stop"`
+}
+
+// CacheConfig configures the cache of generated messages.
+type CacheConfig struct {
+	// Disabled generates every message afresh
+	Disabled bool `json:"disabled"`
+	// TTL is how long a cached message is reused, e.g. "12h"
+	TTL string `json:"ttl"`
+}
+
+// ToneConfig defines a named tone preset.
+type ToneConfig struct {
+	// Description is shown by "snippety tones list"
+	Description string `json:"description"`
+	// Instruction tells the model how to write in this tone
+	Instruction string `json:"instruction"`
+	// Examples are commit messages written in this tone
+	Examples []string `json:"examples"`
+	// Constraints are additional rules, e.g. "Keep the title under 50 characters"
+	Constraints []string `json:"constraints"`
+}
+
+// PromptDir returns the prompt override directory, defaulting to the
+// prompts directory next to the user config file.
+func (c Config) PromptDir() string {
+	if c.Prompts.Dir == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(dir, "snippety", "prompts")
+	}
+	if filepath.IsAbs(c.Prompts.Dir) {
+		return c.Prompts.Dir
+	}
+	if root, err := exec.Command("git", "rev-parse", "--show-toplevel").Output(); err == nil {
+		return filepath.Join(strings.TrimSpace(string(root)), c.Prompts.Dir)
+	}
+	return c.Prompts.Dir
+}
+
+// Load reads the user config file followed by the repository config file.
+// Missing files are not an error.
+func Load() (Config, error) {
+	var cfg Config
+	for _, path := range Paths() {
+		if err := loadFile(path, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+// Paths returns the config files Load reads, in order of precedence from
+// lowest to highest.
+func Paths() []string {
+	var paths []string
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "snippety", "config.json"))
+	}
+	if root, err := exec.Command("git", "rev-parse", "--show-toplevel").Output(); err == nil {
+		paths = append(paths, filepath.Join(strings.TrimSpace(string(root)), RepoFile))
+	}
+	return paths
+}
+
+// loadFile decodes path on top of cfg so that only the keys present in the
+// file override earlier values.
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return nil
+}
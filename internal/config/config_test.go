@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFileOverridesOnlyPresentKeys(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	repo := filepath.Join(dir, "repo.json")
+
+	if err := os.WriteFile(user, []byte(`{"branch": {"pattern": "{type}/{slug}"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(repo, []byte(`{"pairing": {"partners": ["Ada <ada@example.com>"]}, "message": {"wrap": 0}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Config
+	for _, path := range []string{user, repo, filepath.Join(dir, "missing.json")} {
+		if err := loadFile(path, &cfg); err != nil {
+			t.Fatalf("loadFile(%s) unexpected error: %v", path, err)
+		}
+	}
+
+	if cfg.Branch.Pattern != "{type}/{slug}" {
+		t.Errorf("Branch.Pattern = %q, want %q", cfg.Branch.Pattern, "{type}/{slug}")
+	}
+	if cfg.Message.Wrap == nil || *cfg.Message.Wrap != 0 {
+		t.Errorf("Message.Wrap = %v, want 0", cfg.Message.Wrap)
+	}
+	if len(cfg.Pairing.Partners) != 1 || cfg.Pairing.Partners[0] != "Ada <ada@example.com>" {
+		t.Errorf("Pairing.Partners = %q, want %q", cfg.Pairing.Partners, []string{"Ada <ada@example.com>"})
+	}
+}
+
+func TestLoadFileInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"branch": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Config
+	if err := loadFile(path, &cfg); err == nil {
+		t.Error("loadFile() expected an error for invalid JSON")
+	}
+}